@@ -0,0 +1,358 @@
+package oneview
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//APIOptions  - настройки HTTP API
+type APIOptions struct {
+	Token        string //токен Bearer, пустой - без авторизации
+	DefaultLimit int    //размер страницы по умолчанию, 0 - 100
+	MaxLimit     int    //максимальный размер страницы, 0 - 1000
+
+	PublicMetrics bool //отдавать /metrics без токена, метки метрик содержат серийные номера и имена серверов
+}
+
+//ServerListItem  - краткое описание сервера в списке API
+type ServerListItem struct {
+	SerialNumber  string   `json:"serialNumber"`
+	UUID          string   `json:"uuid"`
+	Name          string   `json:"name"`
+	Model         string   `json:"model"`
+	ProcessorType string   `json:"processorType"`
+	MemoryMb      int      `json:"memoryMb"`
+	PowerState    string   `json:"powerState"`
+	Health        string   `json:"health"`
+	Endpoint      string   `json:"endpoint"`
+	Site          string   `json:"site"`
+	Enclosure     string   `json:"enclosure,omitempty"`
+	Bay           int      `json:"bay,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+//NewServerListItem  - краткое описание сервера для списков
+func NewServerListItem(srv *ServerHardware) ServerListItem {
+	item := ServerListItem{
+		SerialNumber:  srv.Base.SerialNumber.String(),
+		UUID:          srv.Base.UUID.String(),
+		Name:          srv.Base.Name,
+		Model:         srv.Base.Model,
+		ProcessorType: srv.Base.ProcessorType,
+		MemoryMb:      srv.Base.MemoryMb,
+		PowerState:    srv.Base.PowerState,
+		Health:        srv.Base.Status,
+		Endpoint:      srv.Endpoint,
+		Site:          srv.Site,
+		Tags:          srv.Tags,
+	}
+	if srv.Enclosure != nil {
+		item.Enclosure = srv.Enclosure.Name
+		item.Bay = serverBay(srv)
+	}
+	return item
+}
+
+//ServerPage  - страница списка серверов
+type ServerPage struct {
+	Total  int              `json:"total"`
+	Offset int              `json:"offset"`
+	Limit  int              `json:"limit"`
+	Items  []ServerListItem `json:"items"`
+}
+
+//EnclosureItem  - корзина и ее сводное состояние
+type EnclosureItem struct {
+	*Enclosure
+	Health EnclosureHealth `json:"health"`
+}
+
+//APIStatus  - состояние снимка и обхода точек подключения
+type APIStatus struct {
+	Ready           bool             `json:"ready"`
+	Updated         time.Time        `json:"updated"`
+	AgeSeconds      float64          `json:"ageSeconds"`
+	DurationSeconds float64          `json:"durationSeconds"`
+	Crawls          int              `json:"crawls"`
+	Servers         int              `json:"servers"`
+	Endpoints       []EndpointStatus `json:"endpoints"`
+	ErrorsTotal     map[string]int   `json:"errorsTotal"`
+}
+
+//apiHandler  - HTTP API только для чтения по снимку Crawler
+type apiHandler struct {
+	crawler *Crawler
+	opt     APIOptions
+}
+
+//APIHandler  - HTTP API только для чтения по последнему снимку обхода:
+//
+//	GET /api/servers?query=...&endpoint=&site=&model=&health=&powerState=&offset=&limit=
+//	GET /api/servers/{serial|uuid|mac}
+//	GET /api/enclosures
+//	GET /api/interconnects
+//	GET /api/status
+func (c *Crawler) APIHandler(opt APIOptions) http.Handler {
+	if opt.DefaultLimit <= 0 {
+		opt.DefaultLimit = 100
+	}
+	if opt.MaxLimit <= 0 {
+		opt.MaxLimit = 1000
+	}
+	return &apiHandler{crawler: c, opt: opt}
+}
+
+//writeError  - ответ с ошибкой в виде {"error": "..."}
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+//bearerAuthorized  - проверка токена Bearer, пустой token - без авторизации
+func bearerAuthorized(r *http.Request, token string) bool {
+	if token == "" {
+		return true
+	}
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(auth[len(prefix):]), []byte(token)) == 1
+}
+
+//writeUnauthorized  - ответ 401 с запросом токена Bearer
+func writeUnauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="oneview"`)
+	writeError(w, http.StatusUnauthorized, "unauthorized")
+}
+
+//requireToken  - обработчик h, доступный только с токеном Bearer
+func requireToken(token string, h http.Handler) http.Handler {
+	if token == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !bearerAuthorized(r, token) {
+			writeUnauthorized(w)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+//etagMatch  - ETag ответа указан в If-None-Match
+func etagMatch(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+//writeJSON  - ответ JSON с ETag по содержимому, 304 при совпадении If-None-Match
+func writeJSON(w http.ResponseWriter, r *http.Request, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatch(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	if r.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+func (h *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !bearerAuthorized(r, h.opt.Token) {
+		writeUnauthorized(w)
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] != "api" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if parts[1] == "status" && len(parts) == 2 {
+		h.status(w, r)
+		return
+	}
+
+	infra := h.crawler.Snapshot()
+	if infra == nil {
+		w.Header().Set("Retry-After", "10")
+		writeError(w, http.StatusServiceUnavailable, "inventory is not loaded yet")
+		return
+	}
+	switch {
+	case parts[1] == "servers" && len(parts) == 2:
+		h.servers(w, r, infra)
+	case parts[1] == "servers" && len(parts) == 3:
+		h.server(w, r, infra, parts[2])
+	case parts[1] == "enclosures" && len(parts) == 2:
+		items := make([]EnclosureItem, 0)
+		for _, enc := range infra.enclosureList() {
+			items = append(items, EnclosureItem{Enclosure: enc, Health: enc.Health()})
+		}
+		writeJSON(w, r, items)
+	case parts[1] == "interconnects" && len(parts) == 2:
+		writeJSON(w, r, infra.InterconnectInventory())
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+//intParam  - целочисленный параметр запроса
+func intParam(r *http.Request, name string, def int) (int, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+//servers  - список серверов с отбором и постраничным выводом
+func (h *apiHandler) servers(w http.ResponseWriter, r *http.Request, infra *OVInfrastructure) {
+	q := r.URL.Query()
+	preds := make([]ServerPredicate, 0)
+	if expr := q.Get("query"); expr != "" {
+		query, err := ParseQuery(expr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		preds = append(preds, query.Predicate())
+	}
+	if v := q.Get("endpoint"); v != "" {
+		preds = append(preds, EndpointIs(v))
+	}
+	if v := q.Get("site"); v != "" {
+		preds = append(preds, func(srv *ServerHardware) bool { return strings.EqualFold(srv.Site, v) })
+	}
+	if v := q.Get("model"); v != "" {
+		preds = append(preds, ModelContains(v))
+	}
+	if v := q.Get("health"); v != "" {
+		preds = append(preds, HealthIs(v))
+	}
+	if v := q.Get("powerState"); v != "" {
+		preds = append(preds, PowerStateIs(v))
+	}
+
+	offset, ok := intParam(r, "offset", 0)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid offset")
+		return
+	}
+	limit, ok := intParam(r, "limit", h.opt.DefaultLimit)
+	if !ok || limit == 0 {
+		writeError(w, http.StatusBadRequest, "invalid limit")
+		return
+	}
+	if limit > h.opt.MaxLimit {
+		limit = h.opt.MaxLimit
+	}
+
+	list := infra.FilterServers(And(preds...))
+	page := ServerPage{Total: len(list), Offset: offset, Limit: limit, Items: make([]ServerListItem, 0)}
+	for i := offset; i < len(list) && i < offset+limit; i++ {
+		page.Items = append(page.Items, NewServerListItem(list[i]))
+	}
+	writeJSON(w, r, page)
+}
+
+//server  - сервер по серийному номеру, UUID или MAC адресу с памятью и хранилищами
+func (h *apiHandler) server(w http.ResponseWriter, r *http.Request, infra *OVInfrastructure, id string) {
+	ix := infra.Index()
+	srv, ok := ix.BySerial(id)
+	if !ok {
+		srv, ok = ix.ByUUID(id)
+	}
+	if !ok {
+		srv, ok = ix.ByMAC(id)
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "server "+id+" not found")
+		return
+	}
+	writeJSON(w, r, srv)
+}
+
+//status  - возраст снимка и состояние обхода точек подключения
+func (h *apiHandler) status(w http.ResponseWriter, r *http.Request) {
+	st := h.crawler.Status()
+	out := APIStatus{
+		Updated:         st.Updated,
+		AgeSeconds:      st.Age().Seconds(),
+		DurationSeconds: st.Duration.Seconds(),
+		Crawls:          st.Crawls,
+		Endpoints:       st.Endpoints,
+		ErrorsTotal:     st.ErrorsTotal,
+	}
+	if infra := h.crawler.Snapshot(); infra != nil {
+		out.Ready = true
+		out.Servers = len(infra.Servers)
+	}
+	//возраст меняется при каждом запросе, ETag для состояния не используется
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	json.NewEncoder(w).Encode(out)
+}
+
+//ServeAPI  - фоновый обход и HTTP сервер с /api/ и /metrics на адресе addr до отмены ctx,
+//для /metrics требуется тот же токен, что и для /api/, если не задано PublicMetrics
+func ServeAPI(ctx context.Context, addr string, c *Crawler, opt APIOptions) error {
+	metrics := c.MetricsHandler()
+	if !opt.PublicMetrics {
+		metrics = requireToken(opt.Token, metrics)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/api/", c.APIHandler(opt))
+	mux.Handle("/metrics", metrics)
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go c.Run(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdown)
+	}()
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
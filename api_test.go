@@ -0,0 +1,47 @@
+package oneview
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HewlettPackard/oneview-golang/ov"
+)
+
+//testCredentialInfra  - инфраструктура с блейд-сервером, в Base которого остался клиент с учетными данными
+func testCredentialInfra() *OVInfrastructure {
+	infra := &OVInfrastructure{}
+	infra.Init()
+	srv := &ServerHardware{Endpoint: "ov1.example.com"}
+	srv.Base.Name = "enc1, bay 1"
+	srv.Base.SerialNumber = "CZ0000001"
+	srv.Base.UUID = "30373237-3132-4D32-3235-303930524D57"
+	srv.Base.Client = &ov.OVClient{}
+	srv.Base.Client.User = "ovadmin"
+	srv.Base.Client.Password = "s3cr3t-pass"
+	srv.Base.Client.Endpoint = "https://ov1.example.com"
+	srv.Enclosure = &Enclosure{Name: "enc1", Endpoint: "ov1.example.com"}
+	infra.Servers = append(infra.Servers, srv)
+	infra.ServersCount = 1
+	return infra
+}
+
+func TestAPIResponsesHaveNoCredentials(t *testing.T) {
+	h := NewSnapshotCrawler(testCredentialInfra(), time.Now()).APIHandler(APIOptions{})
+	for _, path := range []string{"/api/servers", "/api/servers/CZ0000001", "/api/enclosures"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s: status %d: %s", path, rec.Code, rec.Body.String())
+			continue
+		}
+		body := rec.Body.String()
+		for _, secret := range []string{"s3cr3t-pass", "ovadmin", `"Password"`, `"User"`} {
+			if strings.Contains(body, secret) {
+				t.Errorf("GET %s: response contains %s", path, secret)
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package oneview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//FirmwareBaselineComponent  - требование к прошивке модели компонента, задается MinVersion или ExactVersion
+type FirmwareBaselineComponent struct {
+	ComponentType FirmwareComponentType `json:"componentType,omitempty"` //тип компонента, пусто - любой
+	Model         string                `json:"model"`                   //модель компонента или ее начало до пробела: "Smart Array P840"
+	MinVersion    string                `json:"minVersion,omitempty"`    //минимальная допустимая версия
+	ExactVersion  string                `json:"exactVersion,omitempty"`  //точная требуемая версия
+}
+
+//matches  - требование относится к компоненту: модель совпадает без учета регистра или является началом
+//модели компонента до пробела, "Smart Array P840" относится к "Smart Array P840 Controller", но не к "Smart Array P840ar"
+func (c FirmwareBaselineComponent) matches(rec FirmwareRecord) bool {
+	if c.ComponentType != "" && c.ComponentType != rec.ComponentType {
+		return false
+	}
+	model := strings.ToLower(strings.TrimSpace(c.Model))
+	recModel := strings.ToLower(strings.TrimSpace(rec.Model))
+	if model == "" || !strings.HasPrefix(recModel, model) {
+		return false
+	}
+	return len(recModel) == len(model) || recModel[len(model)] == ' '
+}
+
+//check  - проверка версии компонента, возвращает требуемую версию если компонент не соответствует
+func (c FirmwareBaselineComponent) check(rec FirmwareRecord) (string, bool) {
+	if c.ExactVersion != "" && CompareFirmwareVersions(rec.Version, c.ExactVersion) != 0 {
+		return c.ExactVersion, false
+	}
+	if c.MinVersion != "" && CompareFirmwareVersions(rec.Version, c.MinVersion) < 0 {
+		return c.MinVersion, false
+	}
+	return "", true
+}
+
+//FirmwareBaseline  - набор требований к версиям прошивок
+type FirmwareBaseline struct {
+	Name       string                      `json:"name"`
+	Components []FirmwareBaselineComponent `json:"components"`
+}
+
+//LoadFirmwareBaseline  - загрузка набора требований из JSON файла
+//{"name": "2021.10", "components": [{"componentType": "StorageController", "model": "Smart Array P840", "minVersion": "6.60"}]}
+func LoadFirmwareBaseline(path string) (FirmwareBaseline, error) {
+	var baseline FirmwareBaseline
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return baseline, err
+	}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return baseline, err
+	}
+	for i, c := range baseline.Components {
+		if c.Model == "" {
+			return baseline, fmt.Errorf("Baseline component %d has no model", i+1)
+		}
+		if c.MinVersion == "" && c.ExactVersion == "" {
+			return baseline, errors.New("Baseline component " + c.Model + " has neither minVersion nor exactVersion")
+		}
+	}
+	return baseline, nil
+}
+
+//FirmwareViolation  - компонент с прошивкой не соответствующей требованию
+type FirmwareViolation struct {
+	Component FirmwareRecord `json:"Component"` //компонент
+	Required  string         `json:"Required"`  //требуемая версия
+	Exact     bool           `json:"Exact"`     //требуется точное совпадение версии
+}
+
+//FirmwareComplianceState  - результат проверки на соответствие требованиям
+type FirmwareComplianceState string
+
+const (
+	FirmwareCompliant  FirmwareComplianceState = "Compliant"  //все проверенные компоненты соответствуют требованиям
+	FirmwareOutOfDate  FirmwareComplianceState = "OutOfDate"  //есть несоответствующие компоненты
+	FirmwareNotChecked FirmwareComplianceState = "NotChecked" //ни одно требование не относится к компонентам
+)
+
+//FirmwareCompliance  - результат проверки сервера или корзины на соответствие требованиям
+type FirmwareCompliance struct {
+	Kind           string                  `json:"Kind"`           //"server" или "enclosure"
+	Name           string                  `json:"Name"`           //имя в OneView
+	SerialNumber   string                  `json:"SerialNumber"`   //серийный номер
+	State          FirmwareComplianceState `json:"State"`          //результат проверки
+	Compliant      bool                    `json:"Compliant"`      //проверен хотя бы один компонент и все соответствуют требованиям
+	Checked        int                     `json:"Checked"`        //количество проверенных компонентов
+	OutOfDate      []FirmwareViolation     `json:"OutOfDate"`      //несоответствующие компоненты
+	FwBaselineName string                  `json:"FwBaselineName"` //назначенный в OneView baseline (для корзин)
+	FwBaselineURI  string                  `json:"FwBaselineURI"`  //uri назначенного в OneView baseline (для корзин)
+	IsFwManaged    bool                    `json:"IsFwManaged"`    //прошивки корзины управляются OneView
+}
+
+//setResult  - заполнение результата проверки
+func (fc *FirmwareCompliance) setResult(checked int, violations []FirmwareViolation) {
+	fc.Checked = checked
+	fc.OutOfDate = violations
+	switch {
+	case len(violations) > 0:
+		fc.State = FirmwareOutOfDate
+	case checked == 0:
+		fc.State = FirmwareNotChecked
+	default:
+		fc.State = FirmwareCompliant
+	}
+	fc.Compliant = fc.State == FirmwareCompliant
+}
+
+//Check  - проверка списка прошивок на соответствие требованиям, к компоненту применяется требование
+//с самой длинной подходящей моделью
+func (b FirmwareBaseline) Check(records []FirmwareRecord) (int, []FirmwareViolation) {
+	checked := 0
+	violations := make([]FirmwareViolation, 0)
+	for _, rec := range records {
+		best := -1
+		for i, c := range b.Components {
+			if c.matches(rec) && (best < 0 || len(strings.TrimSpace(c.Model)) > len(strings.TrimSpace(b.Components[best].Model))) {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		c := b.Components[best]
+		checked++
+		if required, ok := c.check(rec); !ok {
+			violations = append(violations, FirmwareViolation{Component: rec, Required: required, Exact: c.ExactVersion != ""})
+		}
+	}
+	return checked, violations
+}
+
+//CheckServerFirmware  - проверка сервера на соответствие требованиям
+func (b FirmwareBaseline) CheckServerFirmware(srv *ServerHardware) FirmwareCompliance {
+	fc := FirmwareCompliance{
+		Kind:         "server",
+		Name:         srv.Base.Name,
+		SerialNumber: srv.Base.SerialNumber.String(),
+	}
+	fc.setResult(b.Check(ServerFirmwareInventory(srv)))
+	return fc
+}
+
+//CheckEnclosureFirmware  - проверка корзины на соответствие требованиям
+func (b FirmwareBaseline) CheckEnclosureFirmware(enc *Enclosure) FirmwareCompliance {
+	fc := FirmwareCompliance{
+		Kind:           "enclosure",
+		Name:           enc.Name,
+		SerialNumber:   enc.SerialNumber,
+		FwBaselineName: enc.FwBaselineName,
+		FwBaselineURI:  enc.FwBaselineURI,
+		IsFwManaged:    enc.IsFwManaged,
+	}
+	fc.setResult(b.Check(EnclosureFirmwareInventory(enc)))
+	return fc
+}
+
+//CheckFirmwareBaseline  - проверка всех загруженных серверов и их корзин на соответствие требованиям
+func (infra *OVInfrastructure) CheckFirmwareBaseline(b FirmwareBaseline) []FirmwareCompliance {
+	list := make([]FirmwareCompliance, 0, len(infra.Servers))
+	for _, srv := range infra.Servers {
+		list = append(list, b.CheckServerFirmware(srv))
+	}
+	for _, enc := range infra.enclosureList() {
+		list = append(list, b.CheckEnclosureFirmware(enc))
+	}
+	return list
+}
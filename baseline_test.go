@@ -0,0 +1,73 @@
+package oneview
+
+import "testing"
+
+func TestBaselineComponentMatches(t *testing.T) {
+	tests := []struct {
+		c     FirmwareBaselineComponent
+		model string
+		ctype FirmwareComponentType
+		want  bool
+	}{
+		{FirmwareBaselineComponent{Model: "Smart Array P840"}, "Smart Array P840", FirmwareStorageController, true},
+		{FirmwareBaselineComponent{Model: "Smart Array P840"}, "Smart Array P840 Controller", FirmwareStorageController, true},
+		{FirmwareBaselineComponent{Model: "smart array p840"}, "Smart Array P840 Controller", FirmwareStorageController, true},
+		{FirmwareBaselineComponent{Model: " Smart Array P840 "}, "Smart Array P840", FirmwareStorageController, true},
+		{FirmwareBaselineComponent{Model: "Smart Array P840"}, "Smart Array P840ar", FirmwareStorageController, false},
+		{FirmwareBaselineComponent{Model: "Smart Array P840"}, "Smart Array P84", FirmwareStorageController, false},
+		{FirmwareBaselineComponent{Model: "Smart Array P840", ComponentType: FirmwarePhysicalDrive}, "Smart Array P840", FirmwareStorageController, false},
+		{FirmwareBaselineComponent{Model: ""}, "Smart Array P840", FirmwareStorageController, false},
+	}
+	for _, tt := range tests {
+		rec := FirmwareRecord{ComponentType: tt.ctype, Model: tt.model}
+		if got := tt.c.matches(rec); got != tt.want {
+			t.Errorf("%+v matches %q: got %v, want %v", tt.c, tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestBaselineCheck(t *testing.T) {
+	b := FirmwareBaseline{Components: []FirmwareBaselineComponent{
+		{ComponentType: FirmwareStorageController, Model: "Smart Array", MinVersion: "5.00"},
+		{ComponentType: FirmwareStorageController, Model: "Smart Array P840", MinVersion: "6.60"},
+		{ComponentType: FirmwareILO, Model: "iLO4", ExactVersion: "2.70"},
+	}}
+	records := []FirmwareRecord{
+		{ComponentType: FirmwareStorageController, Model: "Smart Array P840 Controller", Version: "6.30"},
+		{ComponentType: FirmwareStorageController, Model: "Smart Array P440ar", Version: "5.10"},
+		{ComponentType: FirmwareILO, Model: "iLO4", Version: "2.80"},
+		{ComponentType: FirmwarePhysicalDrive, Model: "EG0600FBVFP", Version: "HPD8"},
+	}
+	checked, violations := b.Check(records)
+	if checked != 3 {
+		t.Errorf("checked %d components, want 3", checked)
+	}
+	if len(violations) != 2 {
+		t.Fatalf("violations %+v, want 2", violations)
+	}
+	if v := violations[0]; v.Component.Model != "Smart Array P840 Controller" || v.Required != "6.60" || v.Exact {
+		t.Errorf("P840 violation %+v, want minVersion 6.60 from the longest model", v)
+	}
+	if v := violations[1]; v.Component.Model != "iLO4" || v.Required != "2.70" || !v.Exact {
+		t.Errorf("iLO violation %+v, want exact 2.70", v)
+	}
+}
+
+func TestFirmwareComplianceState(t *testing.T) {
+	tests := []struct {
+		checked    int
+		violations []FirmwareViolation
+		want       FirmwareComplianceState
+	}{
+		{2, nil, FirmwareCompliant},
+		{0, nil, FirmwareNotChecked},
+		{2, []FirmwareViolation{{Required: "6.60"}}, FirmwareOutOfDate},
+	}
+	for _, tt := range tests {
+		var fc FirmwareCompliance
+		fc.setResult(tt.checked, tt.violations)
+		if fc.State != tt.want || fc.Compliant != (tt.want == FirmwareCompliant) {
+			t.Errorf("setResult(%d, %v): state %s, compliant %v, want %s", tt.checked, tt.violations, fc.State, fc.Compliant, tt.want)
+		}
+	}
+}
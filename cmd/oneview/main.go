@@ -0,0 +1,585 @@
+//oneview  - утилита командной строки для работы с инвентаризацией HPE OneView
+//
+//	oneview [-config file] [-snapshot file] [-o table|json|yaml] <команда>
+//
+//	servers list [-query expr] [-model m] [-health h] [-power p] [-endpoint e] [-site s]
+//	server show <serial|uuid|mac>
+//	enclosures list
+//	export -format csv|json|xlsx [-out path]
+//	snapshot save <file>
+//	snapshot diff <old> [<new>]
+//	health
+//	locate [-duration d] [-timeout t] <serial>
+//	tui [-refresh interval]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spa-nsk/oneview"
+)
+
+//endpointConfig  - точка подключения OneView в файле настроек
+type endpointConfig struct {
+	Endpoint    string `json:"endpoint"`    //"https://172.17.100.100"
+	Domain      string `json:"domain"`      //домен авторизации
+	Login       string `json:"login"`       //пользователь
+	Password    string `json:"password"`    //пароль
+	PasswordEnv string `json:"passwordEnv"` //переменная окружения с паролем, если password не задан
+	Site        string `json:"site"`        //площадка (ЦОД)
+}
+
+//config  - файл настроек утилиты
+type config struct {
+	Endpoints []endpointConfig      `json:"endpoints"`
+	Export    oneview.ExportOptions `json:"export"` //столбцы выгрузки, по умолчанию столбцы пакета
+}
+
+//defaultConfigPath  - $ONEVIEW_CONFIG или ~/.oneview.json
+func defaultConfigPath() string {
+	if path := os.Getenv("ONEVIEW_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".oneview.json"
+	}
+	return filepath.Join(home, ".oneview.json")
+}
+
+//loadConfig  - чтение файла настроек
+func loadConfig(path string) (config, error) {
+	var cfg config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+//app  - общие параметры команд
+type app struct {
+	configPath   string
+	snapshotPath string
+	out          *printer
+	cfg          config
+	loadErr      error //ошибки загрузки с точек подключения, данные неполные
+}
+
+//endpoints  - инфраструктура с точками подключения из файла настроек, без загрузки данных
+func (a *app) endpoints() (*oneview.OVInfrastructure, error) {
+	if len(a.cfg.Endpoints) == 0 {
+		return nil, errors.New("no endpoints in " + a.configPath)
+	}
+	infra := oneview.GlobalInitOVInfrastructure()
+	for _, ep := range a.cfg.Endpoints {
+		password := ep.Password
+		if password == "" && ep.PasswordEnv != "" {
+			password = os.Getenv(ep.PasswordEnv)
+		}
+		infra.AddEndpoint(ep.Endpoint, ep.Domain, ep.Login, password)
+		if ep.Site != "" {
+			infra.SetEndpointSite(ep.Endpoint, ep.Site)
+		}
+	}
+	return infra, nil
+}
+
+//infrastructure  - инфраструктура из снимка, если задан -snapshot, иначе загрузка со всех точек подключения,
+//ошибки загрузки сохраняются в a.loadErr и дают ненулевой код завершения после вывода результата
+func (a *app) infrastructure() (*oneview.OVInfrastructure, error) {
+	if a.snapshotPath != "" {
+		infra, _, err := oneview.LoadSnapshot(a.snapshotPath)
+		return infra, err
+	}
+	infra, err := a.endpoints()
+	if err != nil {
+		return nil, err
+	}
+	infra.LoadServerHardwareList()
+	infra.LoadEnclosures()
+	a.checkEndpoints(infra)
+	return infra, nil
+}
+
+//fullInfrastructure  - инфраструктура со всеми данными снимка: для точек подключения дополнительно
+//загружаются коммутационные модули и профили
+func (a *app) fullInfrastructure() (*oneview.OVInfrastructure, error) {
+	infra, err := a.infrastructure()
+	if err != nil {
+		return nil, err
+	}
+	if a.snapshotPath == "" {
+		infra.LoadInterconnects()
+		infra.LoadServerProfiles()
+		a.checkEndpoints(infra)
+	}
+	return infra, nil
+}
+
+//checkEndpoints  - ошибки загрузки с точек подключения в a.loadErr
+func (a *app) checkEndpoints(infra *oneview.OVInfrastructure) {
+	failed := make([]string, 0)
+	for _, st := range infra.EndpointStatuses() {
+		if !st.OK() {
+			failed = append(failed, fmt.Sprintf("%s: %d errors, last: %s", st.Endpoint, st.Errors, st.LastError))
+		}
+	}
+	a.loadErr = nil
+	if len(failed) > 0 {
+		a.loadErr = errors.New("incomplete inventory: " + strings.Join(failed, "; "))
+	}
+}
+
+//usageError  - ошибка в аргументах команды
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: oneview [-config file] [-snapshot file] [-o table|json|yaml] <command>
+
+commands:
+  servers list [-query expr] [-model m] [-health h] [-power p] [-endpoint e] [-site s]
+  server show <serial|uuid|mac>
+  enclosures list
+  export -format csv|json|xlsx [-out path]
+  snapshot save <file>
+  snapshot diff <old> [<new>]
+  health
+  locate [-duration d] [-timeout t] <serial>
+  tui [-refresh interval]
+
+flags:`)
+	flag.PrintDefaults()
+}
+
+func main() {
+	a := &app{}
+	format := flag.String("o", "table", "output format: table, json or yaml")
+	flag.StringVar(&a.configPath, "config", defaultConfigPath(), "endpoints config file")
+	flag.StringVar(&a.snapshotPath, "snapshot", "", "use a saved snapshot instead of crawling the endpoints")
+	flag.Usage = usage
+	flag.Parse()
+
+	switch *format {
+	case "table", "json", "yaml":
+	default:
+		fmt.Fprintln(os.Stderr, "unknown output format", *format)
+		os.Exit(2)
+	}
+	a.out = &printer{w: os.Stdout, format: *format}
+
+	if _, err := os.Stat(a.configPath); err == nil {
+		cfg, err := loadConfig(a.configPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		a.cfg = cfg
+	}
+
+	code, err := a.run(flag.Args())
+	if err == nil {
+		err = a.loadErr //результат выведен, но данные с части точек подключения не загружены
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "oneview:", err)
+		if _, ok := err.(usageError); ok {
+			usage()
+			os.Exit(2)
+		}
+		if code == 0 {
+			code = 1
+		}
+	}
+	os.Exit(code)
+}
+
+//run  - выполнение команды, возвращает код завершения
+func (a *app) run(args []string) (int, error) {
+	if len(args) == 0 {
+		return 2, usageError("command is required")
+	}
+	sub := ""
+	if len(args) > 1 {
+		sub = args[1]
+	}
+	switch {
+	case args[0] == "servers" && sub == "list":
+		return 0, a.serversList(args[2:])
+	case args[0] == "server" && sub == "show":
+		return 0, a.serverShow(args[2:])
+	case args[0] == "enclosures" && sub == "list":
+		return 0, a.enclosuresList()
+	case args[0] == "export":
+		return 0, a.export(args[1:])
+	case args[0] == "snapshot" && sub == "save":
+		return 0, a.snapshotSave(args[2:])
+	case args[0] == "snapshot" && sub == "diff":
+		return 0, a.snapshotDiff(args[2:])
+	case args[0] == "health":
+		return a.health()
+	case args[0] == "locate":
+		return 0, a.locate(args[1:])
+	case args[0] == "tui":
+		return 0, a.tui(args[1:])
+	}
+	return 2, usageError("unknown command " + strings.Join(args, " "))
+}
+
+//serversList  - список серверов с отбором
+func (a *app) serversList(args []string) error {
+	fs := flag.NewFlagSet("servers list", flag.ContinueOnError)
+	query := fs.String("query", "", `filter expression, e.g. 'model ~ "BL460c" and memoryMb >= 262144'`)
+	model := fs.String("model", "", "model contains")
+	health := fs.String("health", "", "health status OK, Warning, Critical")
+	power := fs.String("power", "", "power state On, Off")
+	endpoint := fs.String("endpoint", "", "endpoint")
+	site := fs.String("site", "", "site")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+
+	preds := make([]oneview.ServerPredicate, 0)
+	if *query != "" {
+		q, err := oneview.ParseQuery(*query)
+		if err != nil {
+			return err
+		}
+		preds = append(preds, q.Predicate())
+	}
+	if *model != "" {
+		preds = append(preds, oneview.ModelContains(*model))
+	}
+	if *health != "" {
+		preds = append(preds, oneview.HealthIs(*health))
+	}
+	if *power != "" {
+		preds = append(preds, oneview.PowerStateIs(*power))
+	}
+	if *endpoint != "" {
+		preds = append(preds, oneview.EndpointIs(*endpoint))
+	}
+	if *site != "" {
+		s := *site
+		preds = append(preds, func(srv *oneview.ServerHardware) bool { return strings.EqualFold(srv.Site, s) })
+	}
+
+	infra, err := a.infrastructure()
+	if err != nil {
+		return err
+	}
+	items := make([]oneview.ServerListItem, 0)
+	t := &table{headers: []string{"SERIAL", "NAME", "MODEL", "CPU", "MEMORY MB", "POWER", "HEALTH", "ENDPOINT"}}
+	for _, srv := range infra.FilterServers(oneview.And(preds...)) {
+		item := oneview.NewServerListItem(srv)
+		items = append(items, item)
+		t.add(item.SerialNumber, item.Name, item.Model, fmt.Sprintf("%dx %s", srv.Base.ProcessorCount, item.ProcessorType),
+			item.MemoryMb, item.PowerState, item.Health, item.Endpoint)
+	}
+	return a.out.print(items, t)
+}
+
+//serverShow  - сервер с модулями памяти, контроллерами и дисками
+func (a *app) serverShow(args []string) error {
+	if len(args) != 1 {
+		return usageError("server show requires a serial number, UUID or MAC address")
+	}
+	infra, err := a.infrastructure()
+	if err != nil {
+		return err
+	}
+	srv, err := infra.FindServerHardwareSN(args[0])
+	if err != nil {
+		if srv, err = infra.FindServerHardwareUUID(args[0]); err != nil {
+			var ok bool
+			if srv, ok = infra.Index().ByMAC(args[0]); !ok {
+				return errors.New("server " + args[0] + " not found")
+			}
+		}
+	}
+
+	info := &table{}
+	info.add("Serial:", srv.Base.SerialNumber.String())
+	info.add("Name:", srv.Base.Name)
+	info.add("Model:", srv.Base.Model)
+	info.add("Processors:", fmt.Sprintf("%dx %s, %d cores each", srv.Base.ProcessorCount, srv.Base.ProcessorType, srv.Base.ProcessorCoreCount))
+	info.add("Memory:", fmt.Sprintf("%d MB", srv.Base.MemoryMb))
+	info.add("Power:", srv.Base.PowerState)
+	info.add("Health:", srv.Base.Status)
+	info.add("ROM:", srv.Base.RomVersion)
+	info.add("iLO:", srv.Base.MpFirwareVersion)
+	info.add("Endpoint:", srv.Endpoint)
+	if srv.Enclosure != nil {
+		enc, bay, err := infra.FindServerHardwareBay(srv.Base.SerialNumber.String())
+		if err == nil {
+			info.add("Enclosure:", fmt.Sprintf("%s bay %d", enc.Name, bay))
+		}
+	}
+	if srv.Profile != nil {
+		info.add("Profile:", srv.Profile.Name)
+	}
+
+	dimms := &table{title: "Memory", headers: []string{"LOCATOR", "CAPACITY MIB", "TYPE", "SPEED MHZ", "PART NUMBER", "HEALTH"}}
+	for _, m := range srv.Memory.Data {
+		dimms.add(m.DeviceLocator, m.CapacityMiB, m.MemoryDeviceType, m.OperatingSpeedMhz, m.PartNumber, m.Status.Health)
+	}
+	drives := &table{title: "Drives", headers: []string{"CONTROLLER", "LOCATION", "MODEL", "SERIAL", "MEDIA", "CAPACITY MIB", "FIRMWARE", "HEALTH"}}
+	for _, ctrl := range srv.Storage.Data {
+		for _, d := range ctrl.PhysicalDrives {
+			drives.add(ctrl.Location, d.Location, d.Model, d.SerialNumber, d.MediaType, d.CapacityMiB,
+				d.FirmwareVersion.Current.VersionString, d.Status.Health)
+		}
+	}
+	return a.out.print(srv, info, dimms, drives)
+}
+
+//enclosuresList  - список корзин
+func (a *app) enclosuresList() error {
+	infra, err := a.infrastructure()
+	if err != nil {
+		return err
+	}
+	items := make([]oneview.EnclosureItem, 0)
+	t := &table{headers: []string{"NAME", "SERIAL", "MODEL", "BAYS", "STATUS", "OK", "ENDPOINT"}}
+	for _, enc := range infra.Enclosures {
+		health := enc.Health()
+		occ := enc.BayOccupancy()
+		items = append(items, oneview.EnclosureItem{Enclosure: enc, Health: health})
+		t.add(enc.Name, enc.SerialNumber, enc.EnclosureModel, fmt.Sprintf("%d/%d", occ.Occupied, occ.DeviceBayCount),
+			enc.Status, health.OK(), enc.Endpoint)
+	}
+	return a.out.print(items, t)
+}
+
+//export  - выгрузка серверов, модулей памяти, дисков и контроллеров
+func (a *app) export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "csv", "csv, json or xlsx")
+	out := fs.String("out", "", "directory for csv (default .), file for json (default stdout) and xlsx (default inventory.xlsx)")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	switch *format {
+	case "csv", "json", "xlsx":
+	default:
+		return usageError("unknown export format " + *format)
+	}
+
+	infra, err := a.infrastructure()
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "csv":
+		dir := *out
+		if dir == "" {
+			dir = "."
+		}
+		return infra.ExportCSV(dir, a.cfg.Export)
+	case "xlsx":
+		path := *out
+		if path == "" {
+			path = "inventory.xlsx"
+		}
+		return infra.ExportXLSX(path, a.cfg.Export)
+	}
+
+	//json - таблицы в виде списков объектов с полями по заголовкам столбцов
+	doc := make(map[string][]map[string]string)
+	for _, t := range infra.ExportTables(a.cfg.Export) {
+		rows := make([]map[string]string, 0, len(t.Rows))
+		for _, row := range t.Rows {
+			rec := make(map[string]string)
+			for i, h := range t.Headers {
+				rec[h] = row[i]
+			}
+			rows = append(rows, rec)
+		}
+		doc[t.Name] = rows
+	}
+	w := os.Stdout
+	if *out != "" && *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+//snapshotSave  - сохранение снимка инфраструктуры в файл
+func (a *app) snapshotSave(args []string) error {
+	if len(args) != 1 {
+		return usageError("snapshot save requires a file name")
+	}
+	infra, err := a.fullInfrastructure()
+	if err != nil {
+		return err
+	}
+	if err := infra.SaveSnapshot(args[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "saved %d servers, %d enclosures to %s\n", len(infra.Servers), len(infra.Enclosures), args[0])
+	return nil
+}
+
+//snapshotDiff  - изменения серверов между снимками, без второго снимка - с текущим состоянием
+func (a *app) snapshotDiff(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return usageError("snapshot diff requires one or two snapshot files")
+	}
+	old, _, err := oneview.LoadSnapshot(args[0])
+	if err != nil {
+		return err
+	}
+	var current *oneview.OVInfrastructure
+	if len(args) == 2 {
+		current, _, err = oneview.LoadSnapshot(args[1])
+	} else {
+		current, err = a.fullInfrastructure() //профили входят в сравнение
+	}
+	if err != nil {
+		return err
+	}
+
+	changes := oneview.DiffServers(old, current)
+	t := &table{headers: []string{"SERIAL", "NAME", "CHANGE", "FIELD", "OLD", "NEW"}}
+	for _, c := range changes {
+		if len(c.Changes) == 0 {
+			t.add(c.SerialNumber, c.Name, c.Kind, "", "", "")
+		}
+		for _, f := range c.Changes {
+			t.add(c.SerialNumber, c.Name, c.Kind, f.Field, f.Old, f.New)
+		}
+	}
+	return a.out.print(changes, t)
+}
+
+//locate  - включение индикаторов UID сервера и его корзины на время -duration, Ctrl+C выключает их досрочно
+func (a *app) locate(args []string) error {
+	fs := flag.NewFlagSet("locate", flag.ContinueOnError)
+	duration := fs.Duration("duration", 5*time.Minute, "how long to keep the UID lights on")
+	timeout := fs.Duration("timeout", 2*time.Minute, "wait limit for each OneView task")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	if fs.NArg() != 1 {
+		return usageError("locate requires a serial number")
+	}
+	if a.snapshotPath != "" {
+		return usageError("locate changes UID lights and cannot use -snapshot")
+	}
+	infra, err := a.endpoints()
+	if err != nil {
+		return err
+	}
+	sn := fs.Arg(0)
+	if _, err := infra.LoadServerHardwareSN(sn); err != nil { //только искомый сервер, без обхода всех точек подключения
+		return errors.New("server " + sn + ": " + err.Error())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "turning on UID of %s for %s, press Ctrl+C to turn it off early\n", sn, *duration)
+	if err := infra.LocateServer(ctx, sn, *duration, *timeout); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "UID of %s is off\n", sn)
+	return nil
+}
+
+//healthProblem  - обнаруженная проблема оборудования или обхода
+type healthProblem struct {
+	Kind     string `json:"kind"` //"endpoint", "server", "memory", "drive", "controller", "enclosure"
+	Object   string `json:"object"`
+	Endpoint string `json:"endpoint"`
+	Detail   string `json:"detail"`
+}
+
+//health  - проблемы точек подключения, серверов, памяти, дисков, контроллеров и корзин, код 1 при наличии проблем
+func (a *app) health() (int, error) {
+	infra, err := a.infrastructure()
+	if err != nil {
+		return 1, err
+	}
+	problems := make([]healthProblem, 0)
+	for _, st := range infra.EndpointStatuses() {
+		if !st.OK() {
+			problems = append(problems, healthProblem{"endpoint", st.Endpoint, st.Endpoint, st.LastError})
+		}
+	}
+	for _, srv := range infra.FilterServers(nil) {
+		sn := srv.Base.SerialNumber.String()
+		if oneview.HealthNotOK(srv.Base.Status) {
+			problems = append(problems, healthProblem{"server", sn, srv.Endpoint, "status " + srv.Base.Status})
+		}
+		for _, m := range srv.Memory.Data {
+			if oneview.HealthNotOK(m.Status.Health) {
+				problems = append(problems, healthProblem{"memory", sn + " " + m.DeviceLocator, srv.Endpoint, "health " + m.Status.Health})
+			}
+		}
+		for _, ctrl := range srv.Storage.Data {
+			for _, d := range ctrl.PhysicalDrives {
+				if oneview.HealthNotOK(d.Status.Health) {
+					problems = append(problems, healthProblem{"drive", sn + " " + ctrl.Location + " " + d.Location, srv.Endpoint, "health " + d.Status.Health})
+				}
+			}
+		}
+		for _, h := range oneview.GetControllerHealth(srv) {
+			switch {
+			case oneview.HealthNotOK(h.Status.Health):
+				problems = append(problems, healthProblem{"controller", sn + " " + h.Controller, srv.Endpoint, "health " + h.Status.Health})
+			case h.CacheDegraded():
+				problems = append(problems, healthProblem{"controller", sn + " " + h.Controller, srv.Endpoint, "cache " + h.CacheHealth})
+			case h.BatteryMissing():
+				problems = append(problems, healthProblem{"controller", sn + " " + h.Controller, srv.Endpoint, "cache battery " + h.BatteryStatus})
+			case !h.BoardOK():
+				problems = append(problems, healthProblem{"controller", sn + " " + h.Controller, srv.Endpoint, "board " + h.BoardStatus.Health})
+			}
+		}
+	}
+	for _, enc := range infra.Enclosures {
+		if h := enc.Health(); !h.OK() {
+			problems = append(problems, healthProblem{"enclosure", enc.Name, enc.Endpoint,
+				fmt.Sprintf("status %s, fans %v, power supplies %v, interconnects %v, managers %v",
+					h.Status, h.FansNotOK, h.PowerSuppliesNotOK, h.InterconnectsNotOK, h.ManagersNotOK)})
+		}
+	}
+
+	t := &table{headers: []string{"KIND", "OBJECT", "ENDPOINT", "DETAIL"}}
+	for _, p := range problems {
+		t.add(p.Kind, p.Object, p.Endpoint, p.Detail)
+	}
+	if a.out.format == "table" && len(problems) == 0 {
+		fmt.Fprintf(os.Stdout, "OK: %d servers, %d enclosures\n", len(infra.Servers), len(infra.Enclosures))
+		return 0, nil
+	}
+	if err := a.out.print(problems, t); err != nil {
+		return 1, err
+	}
+	if len(problems) > 0 {
+		return 1, nil
+	}
+	return 0, nil
+}
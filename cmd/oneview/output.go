@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+//printer  - вывод результата команды в формате table, json или yaml
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+//table  - таблица для вывода в формате table
+type table struct {
+	title   string //заголовок таблицы, выводится перед ней если задан
+	headers []string
+	rows    [][]string
+}
+
+//add  - строка таблицы
+func (t *table) add(values ...interface{}) {
+	row := make([]string, 0, len(values))
+	for _, v := range values {
+		row = append(row, fmt.Sprint(v))
+	}
+	t.rows = append(t.rows, row)
+}
+
+//writeTables  - вывод таблиц с выравниванием столбцов
+func writeTables(w io.Writer, tables ...*table) error {
+	for i, t := range tables {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if t.title != "" {
+			fmt.Fprintln(w, t.title)
+		}
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		if len(t.headers) > 0 {
+			fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+		}
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//print  - вывод значения v в формате json или yaml, либо таблиц в формате table
+func (p *printer) print(v interface{}, tables ...*table) error {
+	switch p.format {
+	case "json":
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case "yaml":
+		return writeYAML(p.w, v)
+	default:
+		return writeTables(p.w, tables...)
+	}
+}
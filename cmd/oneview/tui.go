@@ -0,0 +1,710 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spa-nsk/oneview"
+	"golang.org/x/term"
+)
+
+const (
+	ansiReset      = "\x1b[0m"
+	ansiBold       = "\x1b[1m"
+	ansiInverse    = "\x1b[7m"
+	ansiRed        = "\x1b[31m"
+	ansiGreen      = "\x1b[32m"
+	ansiYellow     = "\x1b[33m"
+	ansiClear      = "\x1b[H\x1b[2J"
+	ansiHideCursor = "\x1b[?25l"
+	ansiShowCursor = "\x1b[?25h"
+	ansiAltScreen  = "\x1b[?1049h"
+	ansiMainScreen = "\x1b[?1049l"
+)
+
+//healthRank  - тяжесть состояния: неизвестно, "OK", "Warning", остальные
+func healthRank(health string) int {
+	switch health {
+	case "":
+		return 0
+	case "OK":
+		return 1
+	case "Warning":
+		return 2
+	}
+	return 3
+}
+
+//worstHealth  - наиболее тяжелое из состояний
+func worstHealth(states ...string) string {
+	worst := ""
+	for _, h := range states {
+		if healthRank(h) > healthRank(worst) {
+			worst = h
+		}
+	}
+	return worst
+}
+
+//healthColor  - цвет состояния
+func healthColor(health string) string {
+	switch healthRank(health) {
+	case 1:
+		return ansiGreen
+	case 2:
+		return ansiYellow
+	case 3:
+		return ansiRed
+	}
+	return ""
+}
+
+//serverHealth  - сводное состояние сервера, модулей памяти, контроллеров и дисков
+func serverHealth(srv *oneview.ServerHardware) string {
+	health := srv.Base.Status
+	for _, m := range srv.Memory.Data {
+		health = worstHealth(health, m.Status.Health)
+	}
+	for _, ctrl := range srv.Storage.Data {
+		health = worstHealth(health, ctrl.Status.Health)
+		for _, d := range ctrl.PhysicalDrives {
+			health = worstHealth(health, d.Status.Health)
+		}
+	}
+	return health
+}
+
+//tuiItem  - строка списка
+type tuiItem struct {
+	text   string
+	health string
+	serial string          //сервер строки для открытия iLO
+	open   func() *tuiView //переход на следующий уровень, nil - строка не раскрывается
+}
+
+//tuiView  - уровень навигации, строки строятся по текущему снимку при каждой отрисовке
+type tuiView struct {
+	title  string
+	serial string //сервер уровня для открытия iLO
+	build  func(infra *oneview.OVInfrastructure) []tuiItem
+	cursor int
+	offset int
+}
+
+//tuiState  - состояние интерфейса
+type tuiState struct {
+	crawler   *oneview.Crawler
+	live      bool
+	stack     []*tuiView
+	searching bool
+	query     string
+	message   string
+	notes     chan string //сообщения фоновых действий для строки состояния
+	shown     time.Time   //время снимка на экране
+}
+
+//serverItem  - строка сервера
+func serverItem(prefix string, srv *oneview.ServerHardware) tuiItem {
+	sn := srv.Base.SerialNumber.String()
+	return tuiItem{
+		text:   fmt.Sprintf("%s%-12s %-30s %-4s %s", prefix, sn, srv.Base.Model, srv.Base.PowerState, srv.Base.Name),
+		health: serverHealth(srv),
+		serial: sn,
+		open:   func() *tuiView { return serverView(sn) },
+	}
+}
+
+//endpointNames  - точки подключения снимка, включая известные только по серверам
+func endpointNames(infra *oneview.OVInfrastructure) ([]string, map[string]oneview.EndpointStatus) {
+	statuses := make(map[string]oneview.EndpointStatus)
+	names := make([]string, 0)
+	for _, st := range infra.EndpointStatuses() {
+		statuses[st.Endpoint] = st
+		names = append(names, st.Endpoint)
+	}
+	for _, srv := range infra.Servers {
+		if _, ok := statuses[srv.Endpoint]; !ok {
+			statuses[srv.Endpoint] = oneview.EndpointStatus{Endpoint: srv.Endpoint, Site: srv.Site}
+			names = append(names, srv.Endpoint)
+		}
+	}
+	sort.Strings(names)
+	return names, statuses
+}
+
+//endpointsView  - точки подключения
+func endpointsView() *tuiView {
+	return &tuiView{title: "endpoints", build: func(infra *oneview.OVInfrastructure) []tuiItem {
+		names, statuses := endpointNames(infra)
+		items := make([]tuiItem, 0, len(names))
+		for _, name := range names {
+			ep := name
+			st := statuses[ep]
+			servers := infra.Index().ByEndpoint(ep)
+			health := ""
+			for _, srv := range servers {
+				health = worstHealth(health, serverHealth(srv))
+			}
+			text := fmt.Sprintf("%-40s %-10s %5d servers", ep, st.Site, len(servers))
+			if !st.OK() {
+				health = "Critical"
+				text += "  error: " + st.LastError
+			}
+			items = append(items, tuiItem{text: text, health: health, open: func() *tuiView { return enclosuresView(ep) }})
+		}
+		return items
+	}}
+}
+
+//endpointEnclosures  - корзины точки подключения, загруженные и привязанные к серверам
+func endpointEnclosures(infra *oneview.OVInfrastructure, endpoint string) []*oneview.Enclosure {
+	seen := make(map[string]bool)
+	list := make([]*oneview.Enclosure, 0)
+	add := func(enc *oneview.Enclosure) {
+		if enc != nil && enc.Endpoint == endpoint && !seen[enc.UUID] {
+			seen[enc.UUID] = true
+			list = append(list, enc)
+		}
+	}
+	for _, enc := range infra.Enclosures {
+		add(enc)
+	}
+	for _, srv := range infra.Index().ByEndpoint(endpoint) {
+		add(srv.Enclosure)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+//enclosuresView  - корзины точки подключения и стоечные серверы
+func enclosuresView(endpoint string) *tuiView {
+	return &tuiView{title: endpoint, build: func(infra *oneview.OVInfrastructure) []tuiItem {
+		items := make([]tuiItem, 0)
+		for _, e := range endpointEnclosures(infra, endpoint) {
+			enc := e
+			health := enc.Status
+			if !enc.Health().OK() {
+				health = worstHealth(health, "Warning")
+			}
+			occ := enc.BayOccupancy()
+			items = append(items, tuiItem{
+				text:   fmt.Sprintf("%-24s %-12s %-30s %2d/%-2d bays", enc.Name, enc.SerialNumber, enc.EnclosureModel, occ.Occupied, occ.DeviceBayCount),
+				health: health,
+				open:   func() *tuiView { return baysView(endpoint, enc.UUID, enc.Name) },
+			})
+		}
+		rack := 0
+		health := ""
+		for _, srv := range infra.Index().ByEndpoint(endpoint) {
+			if srv.Enclosure == nil {
+				rack++
+				health = worstHealth(health, serverHealth(srv))
+			}
+		}
+		if rack > 0 {
+			items = append(items, tuiItem{
+				text:   fmt.Sprintf("rack servers (%d)", rack),
+				health: health,
+				open:   func() *tuiView { return rackView(endpoint) },
+			})
+		}
+		return items
+	}}
+}
+
+//baysView  - отсеки корзины
+func baysView(endpoint string, uuid string, name string) *tuiView {
+	return &tuiView{title: name, build: func(infra *oneview.OVInfrastructure) []tuiItem {
+		items := make([]tuiItem, 0)
+		for _, enc := range endpointEnclosures(infra, endpoint) {
+			if enc.UUID != uuid {
+				continue
+			}
+			bays := append([]oneview.EnclosureDeviceBay(nil), enc.DeviceBays...)
+			sort.Slice(bays, func(i, j int) bool {
+				return bays[i].BayNumber < bays[j].BayNumber
+			})
+			for _, bay := range bays {
+				prefix := fmt.Sprintf("bay %2d  ", bay.BayNumber)
+				if srv, ok := infra.Index().ByBay(uuid, bay.BayNumber); ok {
+					items = append(items, serverItem(prefix, srv))
+				} else if bay.DevicePresence == "Subsumed" {
+					items = append(items, tuiItem{text: prefix + "covered by a double-size device"})
+				} else if bay.DevicePresence == "Present" {
+					items = append(items, tuiItem{text: prefix + "device not managed"})
+				} else {
+					items = append(items, tuiItem{text: prefix + "empty"})
+				}
+			}
+		}
+		return items
+	}}
+}
+
+//rackView  - стоечные серверы точки подключения
+func rackView(endpoint string) *tuiView {
+	return &tuiView{title: "rack servers", build: func(infra *oneview.OVInfrastructure) []tuiItem {
+		items := make([]tuiItem, 0)
+		for _, srv := range infra.FilterServers(oneview.EndpointIs(endpoint)) {
+			if srv.Enclosure == nil {
+				items = append(items, serverItem("", srv))
+			}
+		}
+		return items
+	}}
+}
+
+//serverView  - сервер со сводкой по памяти и хранилищам
+func serverView(sn string) *tuiView {
+	return &tuiView{title: sn, serial: sn, build: func(infra *oneview.OVInfrastructure) []tuiItem {
+		srv, ok := infra.Index().BySerial(sn)
+		if !ok {
+			return []tuiItem{{text: "server is not in the current snapshot"}}
+		}
+		memHealth, capacity := "", 0
+		for _, m := range srv.Memory.Data {
+			memHealth = worstHealth(memHealth, m.Status.Health)
+			capacity += m.CapacityMiB
+		}
+		items := []tuiItem{{
+			text:   fmt.Sprintf("Memory modules: %d, %d MiB  >", len(srv.Memory.Data), capacity),
+			health: memHealth,
+			serial: sn,
+			open:   func() *tuiView { return memoryView(sn) },
+		}}
+
+		storHealth, drives := "", 0
+		for _, ctrl := range srv.Storage.Data {
+			storHealth = worstHealth(storHealth, ctrl.Status.Health)
+			for _, d := range ctrl.PhysicalDrives {
+				storHealth = worstHealth(storHealth, d.Status.Health)
+				drives++
+			}
+		}
+		items = append(items, tuiItem{
+			text:   fmt.Sprintf("Storage: %d controllers, %d drives  >", len(srv.Storage.Data), drives),
+			health: storHealth,
+			serial: sn,
+			open:   func() *tuiView { return storageView(sn) },
+		})
+		items = append(items, []tuiItem{
+			{text: "Name:       " + srv.Base.Name},
+			{text: "Model:      " + srv.Base.Model},
+			{text: fmt.Sprintf("Processors: %dx %s", srv.Base.ProcessorCount, srv.Base.ProcessorType)},
+			{text: fmt.Sprintf("Memory:     %d MB", srv.Base.MemoryMb)},
+			{text: "Power:      " + srv.Base.PowerState},
+			{text: "Health:     " + srv.Base.Status, health: srv.Base.Status},
+			{text: "ROM:        " + srv.Base.RomVersion},
+			{text: "iLO:        " + srv.Base.MpFirwareVersion + "  " + srv.Base.GetIloIPAddress()},
+			{text: "Endpoint:   " + srv.Endpoint},
+		}...)
+		if srv.Profile != nil {
+			items = append(items, tuiItem{text: "Profile:    " + srv.Profile.Name})
+		}
+
+		return items
+	}}
+}
+
+//memoryView  - модули памяти сервера
+func memoryView(sn string) *tuiView {
+	return &tuiView{title: "memory", serial: sn, build: func(infra *oneview.OVInfrastructure) []tuiItem {
+		srv, ok := infra.Index().BySerial(sn)
+		if !ok {
+			return nil
+		}
+		items := make([]tuiItem, 0, len(srv.Memory.Data))
+		for _, m := range srv.Memory.Data {
+			items = append(items, tuiItem{
+				text: fmt.Sprintf("%-14s %6d MiB  %-12s %5d MHz  %-22s %s",
+					m.DeviceLocator, m.CapacityMiB, m.MemoryDeviceType, m.OperatingSpeedMhz, m.PartNumber, m.Status.Health),
+				health: m.Status.Health,
+			})
+		}
+		return items
+	}}
+}
+
+//storageView  - контроллеры и диски сервера
+func storageView(sn string) *tuiView {
+	return &tuiView{title: "storage", serial: sn, build: func(infra *oneview.OVInfrastructure) []tuiItem {
+		srv, ok := infra.Index().BySerial(sn)
+		if !ok {
+			return nil
+		}
+		items := make([]tuiItem, 0)
+		for _, ctrl := range srv.Storage.Data {
+			health := ctrl.Status.Health
+			cache := ctrl.CacheModuleStatus.Health
+			if cache != "" {
+				health = worstHealth(health, cache)
+			}
+			items = append(items, tuiItem{
+				text: fmt.Sprintf("%-10s %-32s fw %-10s cache %-8s %s",
+					ctrl.Location, ctrl.Model, ctrl.FirmwareVersion.Current.VersionString, cache, ctrl.Status.Health),
+				health: health,
+			})
+			for _, d := range ctrl.PhysicalDrives {
+				items = append(items, tuiItem{
+					text: fmt.Sprintf("    %-10s %-14s %-14s %-4s %9.0f MiB  fw %-6s %s",
+						d.Location, d.Model, d.SerialNumber, d.MediaType, d.CapacityMiB, d.FirmwareVersion.Current.VersionString, d.Status.Health),
+					health: d.Status.Health,
+				})
+			}
+		}
+		return items
+	}}
+}
+
+//searchView  - серверы, серийный номер которых содержит строку поиска
+func (t *tuiState) searchView() *tuiView {
+	return &tuiView{title: "search", build: func(infra *oneview.OVInfrastructure) []tuiItem {
+		q := strings.ToUpper(t.query)
+		items := make([]tuiItem, 0)
+		for _, srv := range infra.FilterServers(nil) {
+			if strings.Contains(strings.ToUpper(srv.Base.SerialNumber.String()), q) {
+				items = append(items, serverItem("", srv))
+			}
+		}
+		return items
+	}}
+}
+
+//fit  - обрезка строки по ширине экрана
+func fit(s string, width int) string {
+	r := []rune(s)
+	if width < 1 {
+		return ""
+	}
+	if len(r) > width {
+		return string(r[:width])
+	}
+	return s
+}
+
+//top  - текущий уровень
+func (t *tuiState) top() *tuiView {
+	return t.stack[len(t.stack)-1]
+}
+
+//items  - строки текущего уровня по последнему снимку
+func (t *tuiState) items() []tuiItem {
+	infra := t.crawler.Snapshot()
+	if infra == nil {
+		return nil
+	}
+	return t.top().build(infra)
+}
+
+//render  - отрисовка экрана
+func (t *tuiState) render() {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(s + ansiReset + "\x1b[K\r\n")
+	}
+	b.WriteString(ansiClear)
+
+	titles := make([]string, 0, len(t.stack))
+	for _, v := range t.stack {
+		titles = append(titles, v.title)
+	}
+	line(ansiBold + fit(strings.Join(titles, " > "), width))
+
+	status := t.crawler.Status()
+	t.shown = status.Updated
+	infra := t.crawler.Snapshot()
+	switch {
+	case infra == nil:
+		line("loading inventory...")
+	default:
+		source := "snapshot"
+		if t.live {
+			source = "crawl"
+		}
+		line(fit(fmt.Sprintf("%s %s (%s ago), %d servers", source, status.Updated.Format("2006-01-02 15:04:05"),
+			status.Age().Truncate(time.Second), len(infra.Servers)), width))
+	}
+
+	v := t.top()
+	items := t.items()
+	rows := height - 4
+	if rows < 1 {
+		rows = 1
+	}
+	if v.cursor >= len(items) {
+		v.cursor = len(items) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+rows {
+		v.offset = v.cursor - rows + 1
+	}
+	for i := v.offset; i < v.offset+rows; i++ {
+		if i >= len(items) {
+			line("")
+			continue
+		}
+		item := items[i]
+		prefix := "  "
+		if item.open != nil {
+			prefix = "+ "
+		}
+		text := healthColor(item.health) + fit(prefix+item.text, width)
+		if i == v.cursor {
+			text = ansiInverse + text
+		}
+		line(text)
+	}
+
+	switch {
+	case t.searching:
+		line(fit("/"+t.query, width))
+	default:
+		line(fit(t.message, width))
+	}
+	help := "up/down move  enter open  esc back  / search serial  q quit"
+	if t.live {
+		help += "  o iLO  r refresh"
+	}
+	b.WriteString(fit(help, width) + ansiReset)
+	os.Stdout.WriteString(b.String())
+}
+
+//openURL  - открытие ссылки в браузере
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+//openIlo  - вход в iLO сервера через OneView, ссылка содержит сессионный ключ и не выводится на экран,
+//запрос выполняется в фоне, результат передается в t.notes
+func (t *tuiState) openIlo(infra *oneview.OVInfrastructure, sn string) {
+	access, err := infra.IloAccessSN(sn)
+	if access.SsoURL == "" {
+		if err == nil {
+			err = errors.New("no SSO URL returned")
+		}
+		t.notes <- "iLO " + sn + ": " + err.Error()
+		return
+	}
+	if err := openURL(access.SsoURL); err != nil {
+		t.notes <- "iLO " + sn + ": " + err.Error()
+		return
+	}
+	t.notes <- "opened iLO of " + sn
+}
+
+//handle  - обработка клавиши, false - выход
+func (t *tuiState) handle(key string) bool {
+	v := t.top()
+	if t.searching {
+		switch key {
+		case "enter", "down":
+			t.searching = false
+		case "esc":
+			t.searching = false
+			t.stack = t.stack[:len(t.stack)-1]
+		case "backspace":
+			if r := []rune(t.query); len(r) > 0 {
+				t.query = string(r[:len(r)-1])
+			}
+			v.cursor = 0
+		case "ctrl-c":
+			return false
+		default:
+			if r := []rune(key); len(r) == 1 && unicode.IsPrint(r[0]) {
+				t.query += key
+				v.cursor = 0
+			}
+		}
+		return true
+	}
+
+	t.message = ""
+	switch key {
+	case "q", "ctrl-c":
+		return false
+	case "up", "k":
+		v.cursor--
+	case "down", "j":
+		v.cursor++
+	case "pgup":
+		v.cursor -= 10
+	case "pgdown":
+		v.cursor += 10
+	case "enter", "right", "l":
+		items := t.items()
+		if v.cursor >= 0 && v.cursor < len(items) && items[v.cursor].open != nil {
+			t.stack = append(t.stack, items[v.cursor].open())
+		}
+	case "esc", "left", "h", "backspace":
+		if len(t.stack) > 1 {
+			t.stack = t.stack[:len(t.stack)-1]
+		}
+	case "/":
+		if v.title != "search" {
+			t.stack = append(t.stack, t.searchView())
+		}
+		t.searching = true
+		t.query = ""
+	case "o":
+		if !t.live {
+			//в снимке нет учетных данных точек подключения, вход в OneView не выполняется
+			t.message = "iLO is not available in snapshot mode"
+			break
+		}
+		sn := v.serial
+		items := t.items()
+		if v.cursor >= 0 && v.cursor < len(items) && items[v.cursor].serial != "" {
+			sn = items[v.cursor].serial
+		}
+		infra := t.crawler.Snapshot()
+		if sn == "" || infra == nil {
+			t.message = "select a server to open iLO"
+			break
+		}
+		t.message = "opening iLO of " + sn + "..."
+		go t.openIlo(infra, sn)
+	case "r":
+		if t.live {
+			t.message = "refreshing..."
+			go t.crawler.Refresh()
+		}
+	}
+	return true
+}
+
+//readKeys  - чтение клавиш в режиме raw: стрелки, enter, esc, backspace и символы
+func readKeys(keys chan<- string) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		b := buf[:n]
+		switch {
+		case b[0] == 0x1b && n == 1:
+			keys <- "esc"
+		case b[0] == 0x1b && n >= 3 && (b[1] == '[' || b[1] == 'O'):
+			switch b[2] {
+			case 'A':
+				keys <- "up"
+			case 'B':
+				keys <- "down"
+			case 'C':
+				keys <- "right"
+			case 'D':
+				keys <- "left"
+			case '5':
+				keys <- "pgup"
+			case '6':
+				keys <- "pgdown"
+			}
+		case b[0] == 0x1b:
+			keys <- "esc"
+		case b[0] == '\r' || b[0] == '\n':
+			keys <- "enter"
+		case b[0] == 0x7f || b[0] == 0x08:
+			keys <- "backspace"
+		case b[0] == 0x03:
+			keys <- "ctrl-c"
+		default:
+			for _, r := range string(b) {
+				keys <- string(r)
+			}
+		}
+	}
+}
+
+//tui  - интерактивный просмотр: точки подключения > корзины > отсеки > серверы > память и хранилища
+func (a *app) tui(args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	refresh := fs.Duration("refresh", 10*time.Minute, "crawl interval for live mode")
+	if err := fs.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return errors.New("tui requires a terminal")
+	}
+
+	t := &tuiState{notes: make(chan string, 1)}
+	if a.snapshotPath != "" {
+		infra, created, err := oneview.LoadSnapshot(a.snapshotPath)
+		if err != nil {
+			return err
+		}
+		t.crawler = oneview.NewSnapshotCrawler(infra, created)
+	} else {
+		infra, err := a.endpoints()
+		if err != nil {
+			return err
+		}
+		t.crawler = oneview.NewCrawler(infra, *refresh)
+		t.live = true
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go t.crawler.Run(ctx)
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+	os.Stdout.WriteString(ansiAltScreen + ansiHideCursor)
+	defer os.Stdout.WriteString(ansiShowCursor + ansiMainScreen)
+
+	t.stack = []*tuiView{endpointsView()}
+	keys := make(chan string)
+	go readKeys(keys)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	t.render()
+	for {
+		select {
+		case key, ok := <-keys:
+			if !ok || !t.handle(key) {
+				return nil
+			}
+			t.render()
+		case msg := <-t.notes:
+			t.message = msg
+			t.render()
+		case <-ticker.C:
+			if t.crawler.Status().Updated != t.shown {
+				t.render() //завершился обход, снимок обновлен
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+//yamlEntry  - пара ключ, значение объекта с сохранением порядка полей
+type yamlEntry struct {
+	key   string
+	value interface{}
+}
+
+//yamlObject  - объект JSON с полями в исходном порядке
+type yamlObject []yamlEntry
+
+//decodeOrdered  - разбор значения JSON с сохранением порядка полей объектов
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := yamlObject{}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj = append(obj, yamlEntry{keyTok.(string), value})
+			}
+			_, err := dec.Token()
+			return obj, err
+		case '[':
+			list := make([]interface{}, 0)
+			for dec.More() {
+				value, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			_, err := dec.Token()
+			return list, err
+		}
+	}
+	return tok, nil
+}
+
+//yamlScalar  - скалярное значение YAML, строки заключаются в кавычки при необходимости
+func yamlScalar(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(x)
+	case json.Number:
+		return x.String()
+	case string:
+		if yamlNeedsQuotes(x) {
+			return strconv.Quote(x)
+		}
+		return x
+	}
+	return ""
+}
+
+//yamlNeedsQuotes  - строка без кавычек была бы прочитана иначе
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":")
+}
+
+//writeYAMLValue  - вывод значения после ключа или "- ", вложенные поля с отступом indent
+func writeYAMLValue(b *strings.Builder, v interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch x := v.(type) {
+	case yamlObject:
+		if len(x) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		for _, e := range x {
+			b.WriteString(pad + yamlScalar(e.key) + ":")
+			writeYAMLValue(b, e.value, indent+1)
+		}
+	case []interface{}:
+		if len(x) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		for _, item := range x {
+			b.WriteString(pad + "-")
+			writeYAMLItem(b, item, indent+1)
+		}
+	default:
+		b.WriteString(" " + yamlScalar(x) + "\n")
+	}
+}
+
+//writeYAMLItem  - элемент списка: поля объекта начинаются на строке "- "
+func writeYAMLItem(b *strings.Builder, v interface{}, indent int) {
+	obj, ok := v.(yamlObject)
+	if !ok || len(obj) == 0 {
+		writeYAMLValue(b, v, indent)
+		return
+	}
+	pad := strings.Repeat("  ", indent)
+	for i, e := range obj {
+		if i == 0 {
+			b.WriteString(" " + yamlScalar(e.key) + ":")
+		} else {
+			b.WriteString(pad + yamlScalar(e.key) + ":")
+		}
+		writeYAMLValue(b, e.value, indent+1)
+	}
+}
+
+//writeYAML  - вывод значения в YAML через его представление JSON
+func writeYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	doc, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	switch doc.(type) {
+	case yamlObject, []interface{}:
+		writeYAMLValue(&b, doc, 0)
+		_, err = io.WriteString(w, strings.TrimLeft(b.String(), "\n "))
+	default:
+		_, err = io.WriteString(w, yamlScalar(doc)+"\n")
+	}
+	return err
+}
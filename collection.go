@@ -0,0 +1,88 @@
+package oneview
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/HewlettPackard/oneview-golang/ov"
+	"github.com/HewlettPackard/oneview-golang/rest"
+)
+
+//collectionPageSize  - количество объектов запрашиваемых за один запрос
+const collectionPageSize = 100
+
+//collectionPage  - страница коллекции объектов OneView
+type collectionPage struct {
+	Total       int               `json:"total"`
+	Count       int               `json:"count"`
+	Start       int               `json:"start"`
+	NextPageURI string            `json:"nextPageUri"`
+	Members     []json.RawMessage `json:"members"`
+}
+
+//loadCollection  - постраничная загрузка всех объектов коллекции OneView, например "/rest/enclosures"
+func loadCollection(c *ov.OVClient, uri string, filters []string) ([]json.RawMessage, error) {
+	members := make([]json.RawMessage, 0)
+	for start := 0; ; {
+		q := make(map[string]interface{})
+		if len(filters) > 0 {
+			q["filter"] = filters
+		}
+		q["start"] = strconv.Itoa(start)
+		q["count"] = strconv.Itoa(collectionPageSize)
+
+		// refresh login
+		c.RefreshLogin()
+		c.SetAuthHeaderOptions(c.GetAuthHeaderMap())
+
+		data, err := c.RestAPICall(rest.GET, uri, nil, q)
+		if err != nil {
+			return members, err
+		}
+		var page collectionPage
+		if err := json.Unmarshal([]byte(data), &page); err != nil {
+			return members, err
+		}
+		members = append(members, page.Members...)
+		start += len(page.Members)
+		if len(page.Members) == 0 || start >= page.Total {
+			break
+		}
+	}
+	return members, nil
+}
+
+//loadMembers  - загрузка коллекции uri со всех точек подключения в список list: ошибки запроса и разбора
+//элементов учитываются в состоянии обхода точки подключения, объект с уже загруженным ключом key
+//(точка подключения и URI) заменяется новыми данными, новые объекты добавляются в конец списка
+func loadMembers[T any](infra *OVInfrastructure, uri string, list []*T, key func(*T) string, setEndpoint func(*T, string)) ([]*T, error) {
+	var lastErr error
+
+	index := make(map[string]int) //индексы объектов списка по ключу
+	for i, item := range list {
+		index[key(item)] = i
+	}
+	for _, endpoint := range infra.endpoints {
+		members, err := loadCollection(endpoint.client(), uri, nil)
+		if err != nil {
+			infra.statusFor(endpoint).fail(err)
+			lastErr = err
+		}
+		for _, rec := range members {
+			item := new(T)
+			if err := json.Unmarshal(rec, item); err != nil {
+				infra.statusFor(endpoint).fail(err)
+				lastErr = err
+				continue
+			}
+			setEndpoint(item, endpoint.endpoint)
+			if i, ok := index[key(item)]; ok {
+				list[i] = item
+				continue
+			}
+			index[key(item)] = len(list)
+			list = append(list, item)
+		}
+	}
+	return list, lastErr
+}
@@ -0,0 +1,143 @@
+package oneview
+
+import "sort"
+
+//ControllerHealth  - состояние контроллера, его кэша и платы
+type ControllerHealth struct {
+	ServerSerialNumber      string `json:"ServerSerialNumber"`      //серийный номер сервера
+	ServerName              string `json:"ServerName"`              //имя сервера в OneView
+	Controller              string `json:"Controller"`              //расположение контроллера "Slot 3"
+	Model                   string `json:"Model"`                   //модель контроллера
+	SerialNumber            string `json:"SerialNumber"`            //серийный номер контроллера
+	Status                  Status `json:"Status"`                  //состояние контроллера
+	CachePresent            bool   `json:"CachePresent"`            //установлен модуль кэша
+	CacheMemorySizeMiB      int    `json:"CacheMemorySizeMiB"`      //размер кэша в мегабайтах
+	CacheModuleSerialNumber string `json:"CacheModuleSerialNumber"` //серийный номер модуля кэша
+	CacheHealth             string `json:"CacheHealth"`             //состояние модуля кэша, "" если отсутствует или неизвестно
+	BatteryStatus           string `json:"BatteryStatus"`           //батарея кэша "Present", "NotPresent", "" если неизвестно
+	BoardStatus             Status `json:"BoardStatus"`             //состояние платы контроллера
+}
+
+//CacheDegraded  - модуль кэша установлен и его состояние известно и не "OK"
+func (h ControllerHealth) CacheDegraded() bool {
+	return h.CachePresent && h.CacheHealth != "" && h.CacheHealth != "OK"
+}
+
+//BatteryMissing  - модуль кэша установлен без батареи, кэш записи контроллером отключается
+func (h ControllerHealth) BatteryMissing() bool {
+	return h.CachePresent && h.BatteryStatus == "NotPresent"
+}
+
+//BoardOK  - плата контроллера в нормальном состоянии
+func (h ControllerHealth) BoardOK() bool {
+	return h.BoardStatus.Health == "" || h.BoardStatus.Health == "OK"
+}
+
+//GetControllerHealth  - состояние всех контроллеров сервера
+func GetControllerHealth(srv *ServerHardware) []ControllerHealth {
+	list := make([]ControllerHealth, 0, len(srv.Storage.Data))
+	for _, ls := range srv.Storage.Data {
+		list = append(list, ControllerHealth{
+			ServerSerialNumber:      srv.Base.SerialNumber.String(),
+			ServerName:              srv.Base.Name,
+			Controller:              ls.Location,
+			Model:                   ls.Model,
+			SerialNumber:            ls.SerialNumber,
+			Status:                  ls.Status,
+			CachePresent:            ls.CacheMemorySizeMiB > 0 || ls.CacheModuleSerialNumber != "",
+			CacheMemorySizeMiB:      ls.CacheMemorySizeMiB,
+			CacheModuleSerialNumber: ls.CacheModuleSerialNumber,
+			CacheHealth:             ls.CacheModuleStatus.Health,
+			BatteryStatus:           ls.BackupPowerSourceStatus,
+			BoardStatus:             Status{Health: ls.ControllerBoard.Status.Health, State: ls.ControllerBoard.Status.State},
+		})
+	}
+	return list
+}
+
+//ControllerHealthReport  - состояние контроллеров на всех загруженных серверах
+func (infra *OVInfrastructure) ControllerHealthReport() []ControllerHealth {
+	list := make([]ControllerHealth, 0)
+	for _, srv := range infra.Servers {
+		list = append(list, GetControllerHealth(srv)...)
+	}
+	return list
+}
+
+//ControllersWithoutCache  - контроллеры, работающие без модуля кэша
+func (infra *OVInfrastructure) ControllersWithoutCache() []ControllerHealth {
+	list := make([]ControllerHealth, 0)
+	for _, h := range infra.ControllerHealthReport() {
+		if !h.CachePresent {
+			list = append(list, h)
+		}
+	}
+	return list
+}
+
+//ControllersWithoutBattery  - контроллеры с модулем кэша без батареи
+func (infra *OVInfrastructure) ControllersWithoutBattery() []ControllerHealth {
+	list := make([]ControllerHealth, 0)
+	for _, h := range infra.ControllerHealthReport() {
+		if h.BatteryMissing() {
+			list = append(list, h)
+		}
+	}
+	return list
+}
+
+//ControllersWithDegradedCache  - контроллеры с модулем кэша не в состоянии "OK"
+func (infra *OVInfrastructure) ControllersWithDegradedCache() []ControllerHealth {
+	list := make([]ControllerHealth, 0)
+	for _, h := range infra.ControllerHealthReport() {
+		if h.CacheDegraded() {
+			list = append(list, h)
+		}
+	}
+	return list
+}
+
+//ControllerModelHealth  - сводка по состоянию контроллеров одной модели
+type ControllerModelHealth struct {
+	Model         string `json:"Model"`         //модель контроллера
+	Count         int    `json:"Count"`         //количество контроллеров
+	WithoutCache  int    `json:"WithoutCache"`  //без модуля кэша
+	DegradedCache int    `json:"DegradedCache"` //с модулем кэша не в состоянии "OK"
+	NoBattery     int    `json:"NoBattery"`     //с модулем кэша без батареи
+	BoardNotOK    int    `json:"BoardNotOK"`    //с платой не в состоянии "OK"
+	NotOK         int    `json:"NotOK"`         //с состоянием контроллера не "OK"
+}
+
+//ControllerHealthByModel  - сводка по состоянию контроллеров в разрезе моделей, отсортированная по модели
+func (infra *OVInfrastructure) ControllerHealthByModel() []ControllerModelHealth {
+	models := make(map[string]*ControllerModelHealth)
+	for _, h := range infra.ControllerHealthReport() {
+		m, ok := models[h.Model]
+		if !ok {
+			m = &ControllerModelHealth{Model: h.Model}
+			models[h.Model] = m
+		}
+		m.Count++
+		if !h.CachePresent {
+			m.WithoutCache++
+		}
+		if h.CacheDegraded() {
+			m.DegradedCache++
+		}
+		if h.BatteryMissing() {
+			m.NoBattery++
+		}
+		if !h.BoardOK() {
+			m.BoardNotOK++
+		}
+		if h.Status.Health != "" && h.Status.Health != "OK" {
+			m.NotOK++
+		}
+	}
+	list := make([]ControllerModelHealth, 0, len(models))
+	for _, m := range models {
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Model < list[j].Model })
+	return list
+}
@@ -0,0 +1,211 @@
+package oneview
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+//EndpointStatus  - состояние последнего обхода точки подключения
+type EndpointStatus struct {
+	Endpoint  string        `json:"Endpoint"`
+	Site      string        `json:"Site"`
+	Started   time.Time     `json:"Started"`   //начало обхода
+	Duration  time.Duration `json:"Duration"`  //длительность загрузки серверов
+	Servers   int           `json:"Servers"`   //загружено серверов
+	Errors    int           `json:"Errors"`    //ошибок при обходе
+	LastError string        `json:"LastError"` //текст последней ошибки
+}
+
+//OK  - обход выполнен без ошибок
+func (s *EndpointStatus) OK() bool {
+	return s.Errors == 0
+}
+
+//fail  - учет ошибки обхода
+func (s *EndpointStatus) fail(err error) {
+	s.Errors++
+	s.LastError = err.Error()
+}
+
+//finish  - завершение загрузки серверов точки подключения
+func (s *EndpointStatus) finish(servers int) {
+	s.Servers = servers
+	s.Duration = time.Since(s.Started)
+}
+
+//beginCrawl  - новое состояние обхода точки подключения
+func (infra *OVInfrastructure) beginCrawl(endpoint *ovEndpoint) *EndpointStatus {
+	if infra.crawlStatus == nil {
+		infra.crawlStatus = make(map[string]*EndpointStatus)
+	}
+	status := &EndpointStatus{Endpoint: endpoint.endpoint, Site: endpoint.site, Started: time.Now()}
+	infra.crawlStatus[endpoint.endpoint] = status
+	return status
+}
+
+//statusFor  - текущее состояние обхода точки подключения
+func (infra *OVInfrastructure) statusFor(endpoint *ovEndpoint) *EndpointStatus {
+	if status, ok := infra.crawlStatus[endpoint.endpoint]; ok {
+		return status
+	}
+	return infra.beginCrawl(endpoint)
+}
+
+//EndpointStatuses  - состояние последнего обхода всех точек подключения, упорядоченное по точке подключения
+func (infra *OVInfrastructure) EndpointStatuses() []EndpointStatus {
+	list := make([]EndpointStatus, 0, len(infra.endpoints))
+	for _, ep := range infra.endpoints {
+		if status, ok := infra.crawlStatus[ep.endpoint]; ok {
+			list = append(list, *status)
+		} else {
+			list = append(list, EndpointStatus{Endpoint: ep.endpoint, Site: ep.site})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Endpoint < list[j].Endpoint
+	})
+	return list
+}
+
+//CrawlerStatus  - состояние фонового обхода
+type CrawlerStatus struct {
+	Updated     time.Time        `json:"Updated"`     //время завершения последнего обхода, нулевое до первого обхода
+	Duration    time.Duration    `json:"Duration"`    //длительность последнего обхода
+	Crawls      int              `json:"Crawls"`      //выполнено обходов
+	Endpoints   []EndpointStatus `json:"Endpoints"`   //состояние точек подключения в последнем обходе
+	ErrorsTotal map[string]int   `json:"ErrorsTotal"` //накопленное количество ошибок по точкам подключения
+}
+
+//Age  - возраст снимка инфраструктуры
+func (s CrawlerStatus) Age() time.Duration {
+	if s.Updated.IsZero() {
+		return 0
+	}
+	return time.Since(s.Updated)
+}
+
+//Crawler  - периодическая загрузка инфраструктуры в фоне, потребители получают последний полный снимок
+type Crawler struct {
+	Interval time.Duration //интервал между обходами
+
+	endpoints []*ovEndpoint
+	static    bool       //неизменный снимок NewSnapshotCrawler, обход не выполняется
+	crawlMu   sync.Mutex //один обход в каждый момент времени
+
+	mu          sync.RWMutex
+	infra       *OVInfrastructure
+	updated     time.Time
+	duration    time.Duration
+	crawls      int
+	errorsTotal map[string]int
+}
+
+//NewCrawler  - фоновый обход точек подключения, добавленных в infra через AddEndpoint
+func NewCrawler(infra *OVInfrastructure, interval time.Duration) *Crawler {
+	return &Crawler{
+		Interval:    interval,
+		endpoints:   infra.endpoints,
+		errorsTotal: make(map[string]int),
+	}
+}
+
+//Refresh  - обход всех точек подключения (серверы, корзины, коммутационные модули и профили) и замена снимка,
+//при ошибках снимок все равно заменяется, для NewSnapshotCrawler возвращает ошибку без изменения снимка
+func (c *Crawler) Refresh() error {
+	if c.static {
+		return errors.New("Snapshot crawler cannot be refreshed")
+	}
+	c.crawlMu.Lock()
+	defer c.crawlMu.Unlock()
+
+	started := time.Now()
+	infra := &OVInfrastructure{}
+	infra.Init()
+	infra.endpoints = c.endpoints
+
+	infra.LoadServerHardwareList()
+	_, err := infra.LoadEnclosures()
+	if _, ierr := infra.LoadInterconnects(); err == nil {
+		err = ierr
+	}
+	if _, perr := infra.LoadServerProfiles(); err == nil {
+		err = perr
+	}
+	infra.Index() //индексы строятся до публикации снимка, дальше снимок только читается
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.infra = infra
+	c.updated = time.Now()
+	c.duration = c.updated.Sub(started)
+	c.crawls++
+	for _, status := range infra.EndpointStatuses() {
+		c.errorsTotal[status.Endpoint] += status.Errors
+		if err == nil && status.Errors > 0 {
+			err = &crawlError{status.Endpoint, status.LastError}
+		}
+	}
+	return err
+}
+
+//crawlError  - ошибка обхода точки подключения
+type crawlError struct {
+	endpoint string
+	msg      string
+}
+
+func (e *crawlError) Error() string {
+	return e.endpoint + ": " + e.msg
+}
+
+//Run  - обход сразу и далее с интервалом Interval до отмены ctx, при Interval <= 0 - однократный обход,
+//для NewSnapshotCrawler обход не выполняется
+func (c *Crawler) Run(ctx context.Context) {
+	if !c.static {
+		c.Refresh()
+	}
+	if c.static || c.Interval <= 0 {
+		<-ctx.Done()
+		return
+	}
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.Refresh()
+		}
+	}
+}
+
+//Snapshot  - последний снимок инфраструктуры, nil до завершения первого обхода, изменять снимок нельзя
+func (c *Crawler) Snapshot() *OVInfrastructure {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.infra
+}
+
+//Status  - состояние фонового обхода
+func (c *Crawler) Status() CrawlerStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	status := CrawlerStatus{
+		Updated:     c.updated,
+		Duration:    c.duration,
+		Crawls:      c.crawls,
+		Endpoints:   make([]EndpointStatus, 0),
+		ErrorsTotal: make(map[string]int),
+	}
+	if c.infra != nil {
+		status.Endpoints = c.infra.EndpointStatuses()
+	}
+	for k, v := range c.errorsTotal {
+		status.ErrorsTotal[k] = v
+	}
+	return status
+}
@@ -0,0 +1,419 @@
+package oneview
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/HewlettPackard/oneview-golang/ov"
+	"github.com/HewlettPackard/oneview-golang/rest"
+	"github.com/HewlettPackard/oneview-golang/utils"
+)
+
+//EnclosureIPSetting  - настройки адреса устройства в отсеке корзины
+type EnclosureIPSetting struct {
+	IPAddress  string `json:"ipAddress"`
+	Mode       string `json:"mode"`
+	IPRangeURI string `json:"ipRangeUri"`
+}
+
+//EnclosureDeviceBay  - отсек корзины для блейд-сервера
+type EnclosureDeviceBay struct {
+	Type                                    string              `json:"type"`
+	BayNumber                               int                 `json:"bayNumber"`
+	Model                                   string              `json:"model"`
+	DevicePresence                          string              `json:"devicePresence"` //"Present", "Absent", "Subsumed"
+	ProfileURI                              string              `json:"profileUri"`
+	DeviceURI                               string              `json:"deviceUri"` //uri сервера в отсеке
+	CoveredByProfile                        string              `json:"coveredByProfile"`
+	CoveredByDevice                         string              `json:"coveredByDevice"`
+	Ipv4Setting                             *EnclosureIPSetting `json:"ipv4Setting"`
+	Ipv6Setting                             *EnclosureIPSetting `json:"ipv6Setting"`
+	URI                                     string              `json:"uri"`
+	Category                                string              `json:"category"`
+	ETag                                    string              `json:"eTag"`
+	Created                                 string              `json:"created"`
+	Modified                                string              `json:"modified"`
+	AvailableForHalfHeightProfile           bool                `json:"availableForHalfHeightProfile"`
+	AvailableForFullHeightProfile           bool                `json:"availableForFullHeightProfile"`
+	DeviceBayType                           string              `json:"deviceBayType"`
+	DeviceFormFactor                        string              `json:"deviceFormFactor"`
+	BayPowerState                           string              `json:"bayPowerState"`
+	ChangeState                             string              `json:"changeState"`
+	AvailableForHalfHeightDoubleWideProfile bool                `json:"availableForHalfHeightDoubleWideProfile"`
+	AvailableForFullHeightDoubleWideProfile bool                `json:"availableForFullHeightDoubleWideProfile"`
+	UUID                                    string              `json:"uuid"`
+}
+
+//EnclosureInterconnectBay  - отсек корзины для коммутационного модуля
+type EnclosureInterconnectBay struct {
+	BayNumber              int                 `json:"bayNumber"`
+	InterconnectURI        string              `json:"interconnectUri"`
+	LogicalInterconnectURI string              `json:"logicalInterconnectUri"`
+	InterconnectModel      string              `json:"interconnectModel"`
+	Ipv4Setting            *EnclosureIPSetting `json:"ipv4Setting"`
+	Ipv6Setting            *EnclosureIPSetting `json:"ipv6Setting"`
+	SerialNumber           string              `json:"serialNumber"`
+	InterconnectBayType    string              `json:"interconnectBayType"`
+	ChangeState            string              `json:"changeState"`
+	BayPowerState          string              `json:"bayPowerState"`
+	Status                 string              `json:"status,omitempty"` //состояние модуля, заполняется из LoadInterconnects
+}
+
+//EnclosureFanBay  - отсек корзины для вентилятора
+type EnclosureFanBay struct {
+	BayNumber       int    `json:"bayNumber"`
+	DevicePresence  string `json:"devicePresence"`
+	DeviceRequired  bool   `json:"deviceRequired"`
+	Status          string `json:"status"`
+	Model           string `json:"model"`
+	PartNumber      string `json:"partNumber"`
+	SparePartNumber string `json:"sparePartNumber"`
+	FanBayType      string `json:"fanBayType"`
+	ChangeState     string `json:"changeState"`
+	State           string `json:"state"`
+}
+
+//EnclosurePowerSupplyBay  - отсек корзины для блока питания
+type EnclosurePowerSupplyBay struct {
+	BayNumber          int    `json:"bayNumber"`
+	DevicePresence     string `json:"devicePresence"`
+	Status             string `json:"status"`
+	Model              string `json:"model"`
+	SerialNumber       string `json:"serialNumber"`
+	PartNumber         string `json:"partNumber"`
+	SparePartNumber    string `json:"sparePartNumber"`
+	PowerSupplyBayType string `json:"powerSupplyBayType"`
+	ChangeState        string `json:"changeState"`
+}
+
+//EnclosureManagerBay  - отсек корзины для менеджера (Onboard Administrator)
+type EnclosureManagerBay struct {
+	BayNumber      int           `json:"bayNumber"`
+	ManagerType    string        `json:"managerType"`
+	UIDState       string        `json:"uidState"`
+	BayPowerState  string        `json:"bayPowerState"`
+	FwVersion      string        `json:"fwVersion"`
+	DevicePresence string        `json:"devicePresence"`
+	Role           string        `json:"role"` //"Active", "Standby"
+	IPAddress      string        `json:"ipAddress"`
+	ChangeState    string        `json:"changeState"`
+	FwBuildDate    string        `json:"fwBuildDate"`
+	DhcpIpv6Enable bool          `json:"dhcpIpv6Enable"`
+	Ipv6Addresses  []interface{} `json:"ipv6Addresses"`
+	FqdnHostName   string        `json:"fqdnHostName"`
+	DhcpEnable     bool          `json:"dhcpEnable"`
+	Status         string        `json:"status"`
+	LinkPortState  string        `json:"linkPortState"`     //состояние порта связи менеджеров "Linked", "Unlinked"
+	MgmtPortState  string        `json:"mgmtPortLinkState"` //состояние порта управления "Linked", "Unlinked"
+}
+
+//Reachability  - доступность менеджера по состоянию питания и портов: "Reachable", "Unreachable" или "Unknown",
+//если OneView не сообщает состояние портов, адрес менеджера доступность не подтверждает
+func (bay EnclosureManagerBay) Reachability() string {
+	switch {
+	case !bayPowered(bay.BayPowerState) || bay.LinkPortState == "Unlinked" || bay.MgmtPortState == "Unlinked":
+		return "Unreachable"
+	case bay.LinkPortState == "Linked" || bay.MgmtPortState == "Linked":
+		return "Reachable"
+	}
+	return "Unknown"
+}
+
+//RemoteSupportSettings  - настройки удаленной поддержки HPE
+type RemoteSupportSettings struct {
+	RemoteSupportCurrentState string `json:"remoteSupportCurrentState"`
+	Destination               string `json:"destination"`
+}
+
+//Enclosure  - корзина (шасси) для блейд-серверов
+type Enclosure struct {
+	Type                       string                     `json:"type"`
+	URI                        string                     `json:"uri"`
+	Category                   string                     `json:"category"`
+	ETag                       time.Time                  `json:"eTag"`
+	Created                    time.Time                  `json:"created"`
+	Modified                   time.Time                  `json:"modified"`
+	RefreshState               string                     `json:"refreshState"`
+	StateReason                string                     `json:"stateReason"`
+	EnclosureType              string                     `json:"enclosureType"`
+	EnclosureTypeURI           string                     `json:"enclosureTypeUri"`
+	EnclosureModel             string                     `json:"enclosureModel"`
+	UUID                       string                     `json:"uuid"`
+	SerialNumber               string                     `json:"serialNumber"`
+	PartNumber                 string                     `json:"partNumber"`
+	ReconfigurationState       string                     `json:"reconfigurationState"`
+	UIDState                   string                     `json:"uidState"`
+	LicensingIntent            string                     `json:"licensingIntent"`
+	DeviceBayCount             int                        `json:"deviceBayCount"`
+	DeviceBays                 []EnclosureDeviceBay       `json:"deviceBays"`
+	InterconnectBayCount       int                        `json:"interconnectBayCount"`
+	InterconnectBays           []EnclosureInterconnectBay `json:"interconnectBays"`
+	FanBayCount                int                        `json:"fanBayCount"`
+	FanBays                    []EnclosureFanBay          `json:"fanBays"`
+	PowerSupplyBayCount        int                        `json:"powerSupplyBayCount"`
+	PowerSupplyBays            []EnclosurePowerSupplyBay  `json:"powerSupplyBays"`
+	EnclosureGroupURI          string                     `json:"enclosureGroupUri"`
+	FwBaselineURI              string                     `json:"fwBaselineUri"`
+	FwBaselineName             string                     `json:"fwBaselineName"`
+	IsFwManaged                bool                       `json:"isFwManaged"`
+	ForceInstallFirmware       bool                       `json:"forceInstallFirmware"`
+	LogicalEnclosureURI        string                     `json:"logicalEnclosureUri"`
+	ManagerBays                []EnclosureManagerBay      `json:"managerBays"`
+	SupportState               string                     `json:"supportState"`
+	SupportDataCollectionState string                     `json:"supportDataCollectionState"`
+	SupportDataCollectionType  string                     `json:"supportDataCollectionType"`
+	SupportDataCollectionsURI  string                     `json:"supportDataCollectionsUri"`
+	RemoteSupportURI           string                     `json:"remoteSupportUri"`
+	RemoteSupportSettings      RemoteSupportSettings      `json:"remoteSupportSettings"`
+	CrossBars                  []interface{}              `json:"crossBars"`
+	Partitions                 []interface{}              `json:"partitions"`
+	ScopesURI                  string                     `json:"scopesUri"`
+	Status                     string                     `json:"status"`
+	Name                       string                     `json:"name"`
+	State                      string                     `json:"state"`
+	Description                string                     `json:"description"`
+	StandbyOaPreferredIP       string                     `json:"standbyOaPreferredIP"`
+	ActiveOaPreferredIP        string                     `json:"activeOaPreferredIP"`
+	AssetTag                   string                     `json:"assetTag"`
+	RackName                   string                     `json:"rackName"`
+	VcmDomainID                string                     `json:"vcmDomainId"`
+	VcmDomainName              string                     `json:"vcmDomainName"`
+	VcmMode                    bool                       `json:"vcmMode"`
+	VcmURL                     string                     `json:"vcmUrl"`
+	OaBays                     int                        `json:"oaBays"`
+	MigrationState             string                     `json:"migrationState"`
+	Endpoint                   string                     `json:"endpoint,omitempty"` //точка подключения OneView, с которой загружена корзина
+}
+
+//GetServerEnclosure  - запрос корзины по uri (LocationURI блейд-сервера)
+func GetServerEnclosure(c *ov.OVClient, encuri utils.Nstring) (Enclosure, error) {
+
+	var (
+		encHardware Enclosure
+	)
+
+	// refresh login
+	c.RefreshLogin()
+	c.SetAuthHeaderOptions(c.GetAuthHeaderMap())
+
+	// rest call
+	data, err := c.RestAPICall(rest.GET, encuri.String(), nil)
+	if err != nil {
+		return encHardware, err
+	}
+
+	if err := json.Unmarshal([]byte(data), &encHardware); err != nil {
+		return encHardware, err
+	}
+	return encHardware, nil
+}
+
+//EnclosureHealth  - сводное состояние корзины, номера отсеков с компонентами не в состоянии "OK"
+type EnclosureHealth struct {
+	EnclosureName      string `json:"EnclosureName"`
+	Status             string `json:"Status"`             //состояние корзины в OneView
+	FansNotOK          []int  `json:"FansNotOK"`          //вентиляторы не "OK" или отсутствующие обязательные
+	PowerSuppliesNotOK []int  `json:"PowerSuppliesNotOK"` //блоки питания не "OK"
+	InterconnectsNotOK []int  `json:"InterconnectsNotOK"` //коммутационные модули не включены или не "OK"
+	ManagersNotOK      []int  `json:"ManagersNotOK"`      //менеджеры не включены или не "OK"
+}
+
+//OK  - все компоненты корзины в нормальном состоянии
+func (h EnclosureHealth) OK() bool {
+	return (h.Status == "" || h.Status == "OK") &&
+		len(h.FansNotOK) == 0 && len(h.PowerSuppliesNotOK) == 0 &&
+		len(h.InterconnectsNotOK) == 0 && len(h.ManagersNotOK) == 0
+}
+
+//bayPowered  - отсек включен или состояние питания неизвестно
+func bayPowered(state string) bool {
+	return state == "" || state == "On"
+}
+
+//Health  - сводное состояние вентиляторов, блоков питания, коммутационных модулей и менеджеров корзины
+func (enc *Enclosure) Health() EnclosureHealth {
+	h := EnclosureHealth{
+		EnclosureName:      enc.Name,
+		Status:             enc.Status,
+		FansNotOK:          make([]int, 0),
+		PowerSuppliesNotOK: make([]int, 0),
+		InterconnectsNotOK: make([]int, 0),
+		ManagersNotOK:      make([]int, 0),
+	}
+	for _, bay := range enc.FanBays {
+		switch {
+		case bay.DevicePresence == "Present" && bay.Status != "OK":
+			h.FansNotOK = append(h.FansNotOK, bay.BayNumber)
+		case bay.DevicePresence != "Present" && bay.DeviceRequired:
+			h.FansNotOK = append(h.FansNotOK, bay.BayNumber)
+		}
+	}
+	for _, bay := range enc.PowerSupplyBays {
+		if bay.DevicePresence == "Present" && bay.Status != "OK" {
+			h.PowerSuppliesNotOK = append(h.PowerSuppliesNotOK, bay.BayNumber)
+		}
+	}
+	for _, bay := range enc.InterconnectBays {
+		if bay.InterconnectURI != "" && (!bayPowered(bay.BayPowerState) || HealthNotOK(bay.Status)) {
+			h.InterconnectsNotOK = append(h.InterconnectsNotOK, bay.BayNumber)
+		}
+	}
+	for _, bay := range enc.ManagerBays {
+		if bay.DevicePresence == "Present" && (!bayPowered(bay.BayPowerState) || HealthNotOK(bay.Status)) {
+			h.ManagersNotOK = append(h.ManagersNotOK, bay.BayNumber)
+		}
+	}
+	return h
+}
+
+//LoadEnclosures  - загрузка всех корзин со всех точек подключения, в том числе корзин без обнаруженных серверов,
+//при повторной загрузке ранее загруженные корзины заменяются новыми данными
+func (infra *OVInfrastructure) LoadEnclosures() ([]*Enclosure, error) {
+	var lastErr error
+
+	loaded := make(map[string]int) //индекс корзины в infra.Enclosures по UUID
+	for i, enc := range infra.Enclosures {
+		loaded[enc.UUID] = i
+	}
+	seen := make(map[string]bool) //корзины, загруженные в этот раз
+	for _, endpoint := range infra.endpoints {
+		members, err := loadCollection(endpoint.client(), "/rest/enclosures", nil)
+		if err != nil {
+			infra.statusFor(endpoint).fail(err)
+			lastErr = err
+		}
+		for _, rec := range members {
+			enc := Enclosure{}
+			if err := json.Unmarshal(rec, &enc); err != nil {
+				lastErr = err
+				continue
+			}
+			if seen[enc.UUID] { //корзина доступна с нескольких точек подключения
+				continue
+			}
+			seen[enc.UUID] = true
+			enc.Endpoint = endpoint.endpoint
+			if i, ok := loaded[enc.UUID]; ok {
+				infra.Enclosures[i] = &enc
+				continue
+			}
+			loaded[enc.UUID] = len(infra.Enclosures)
+			infra.Enclosures = append(infra.Enclosures, &enc)
+			infra.EnclosuresCount++
+		}
+	}
+	infra.linkEnclosures()
+	return infra.Enclosures, lastErr
+}
+
+//linkInterconnectStatus  - состояние загруженных коммутационных модулей в отсеках корзин
+func (infra *OVInfrastructure) linkInterconnectStatus() {
+	if len(infra.Interconnects) == 0 {
+		return
+	}
+	status := make(map[string]string)
+	for _, ic := range infra.Interconnects {
+		status[ic.Endpoint+ic.URI] = ic.Status
+	}
+	for _, enc := range infra.enclosureList() {
+		for i := range enc.InterconnectBays {
+			bay := &enc.InterconnectBays[i]
+			if st, ok := status[enc.Endpoint+bay.InterconnectURI]; ok {
+				bay.Status = st
+			}
+		}
+	}
+}
+
+//linkEnclosures  - привязка загруженных корзин к блейд-серверам по LocationURI и пересчет состояния корзин серверов
+func (infra *OVInfrastructure) linkEnclosures() {
+	infra.linkInterconnectStatus()
+	byURI := make(map[string]*Enclosure)
+	for _, enc := range infra.Enclosures {
+		byURI[enc.Endpoint+enc.URI] = enc
+	}
+	for _, srv := range infra.Servers {
+		enc, ok := byURI[srv.Endpoint+srv.Base.LocationURI.String()]
+		if !ok {
+			enc = srv.Enclosure //корзина, запрошенная при загрузке сервера
+		}
+		if enc == nil {
+			continue
+		}
+		health := enc.Health()
+		srv.Enclosure = enc
+		srv.EnclosureHealth = &health
+	}
+	infra.index = nil
+}
+
+//FindEnclosure  - поиск загруженной корзины по имени, серийному номеру или UUID
+func (infra *OVInfrastructure) FindEnclosure(id string) (*Enclosure, error) {
+	for _, enc := range infra.enclosureList() {
+		if enc.Name == id || enc.SerialNumber == id || enc.UUID == id {
+			return enc, nil
+		}
+	}
+	return nil, errors.New("Enclosure " + id + " not found")
+}
+
+//FindServerHardwareBay  - корзина и номер отсека блейд-сервера с указанным серийным номером
+func (infra *OVInfrastructure) FindServerHardwareBay(sn string) (*Enclosure, int, error) {
+	srv, err := infra.FindServerHardwareSN(sn)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, enc := range infra.enclosureList() {
+		if enc.Endpoint != "" && enc.Endpoint != srv.Endpoint {
+			continue
+		}
+		for _, bay := range enc.DeviceBays {
+			if bay.DeviceURI != "" && bay.DeviceURI == srv.Base.URI.String() {
+				return enc, bay.BayNumber, nil
+			}
+		}
+	}
+	if srv.Enclosure != nil && srv.Base.Position > 0 {
+		return srv.Enclosure, srv.Base.Position, nil
+	}
+	return nil, 0, errors.New("Server " + sn + " is not in an enclosure")
+}
+
+//FindServerHardwareInBay  - блейд-сервер в отсеке корзины, корзина задается именем, серийным номером или UUID
+func (infra *OVInfrastructure) FindServerHardwareInBay(enclosure string, bayNumber int) (*ServerHardware, error) {
+	enc, err := infra.FindEnclosure(enclosure)
+	if err != nil {
+		return nil, err
+	}
+	for _, bay := range enc.DeviceBays {
+		if bay.BayNumber != bayNumber || bay.DeviceURI == "" {
+			continue
+		}
+		for _, srv := range infra.Servers {
+			if srv.Base.URI.String() == bay.DeviceURI && (enc.Endpoint == "" || srv.Endpoint == enc.Endpoint) {
+				return srv, nil
+			}
+		}
+	}
+	return nil, errors.New("No server in bay " + strconv.Itoa(bayNumber) + " of enclosure " + enclosure)
+}
+
+//enclosureList  - загруженные корзины и корзины загруженных блейд-серверов без повторов
+func (infra *OVInfrastructure) enclosureList() []*Enclosure {
+	list := make([]*Enclosure, 0, len(infra.Enclosures))
+	seen := make(map[string]bool)
+	for _, enc := range infra.Enclosures {
+		seen[enc.UUID] = true
+		list = append(list, enc)
+	}
+	for _, srv := range infra.Servers {
+		if srv.Enclosure == nil || seen[srv.Enclosure.UUID] {
+			continue
+		}
+		seen[srv.Enclosure.UUID] = true
+		list = append(list, srv.Enclosure)
+	}
+	return list
+}
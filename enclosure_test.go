@@ -0,0 +1,77 @@
+package oneview
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnclosureHealth(t *testing.T) {
+	enc := &Enclosure{
+		Name:   "enc1",
+		Status: "Warning",
+		FanBays: []EnclosureFanBay{
+			{BayNumber: 1, DevicePresence: "Present", Status: "OK"},
+			{BayNumber: 2, DevicePresence: "Absent", DeviceRequired: true},
+			{BayNumber: 3, DevicePresence: "Absent"},
+		},
+		PowerSupplyBays: []EnclosurePowerSupplyBay{
+			{BayNumber: 1, DevicePresence: "Present", Status: "OK"},
+			{BayNumber: 2, DevicePresence: "Present", Status: "Critical"},
+		},
+		InterconnectBays: []EnclosureInterconnectBay{
+			{BayNumber: 1, InterconnectURI: "/rest/interconnects/1", BayPowerState: "On", Status: "OK"},
+			{BayNumber: 2, InterconnectURI: "/rest/interconnects/2", BayPowerState: "On", Status: "Warning"},
+			{BayNumber: 3, InterconnectURI: "/rest/interconnects/3", BayPowerState: "Off"},
+			{BayNumber: 4, InterconnectURI: "/rest/interconnects/4", BayPowerState: "On"},
+			{BayNumber: 5, BayPowerState: "Off"},
+		},
+		ManagerBays: []EnclosureManagerBay{
+			{BayNumber: 1, DevicePresence: "Present", BayPowerState: "On", Status: "Critical"},
+			{BayNumber: 2, DevicePresence: "Present", BayPowerState: "On", Status: "OK"},
+			{BayNumber: 3, DevicePresence: "Present", BayPowerState: "Off", Status: "OK"},
+			{BayNumber: 4, DevicePresence: "Absent", Status: "Disabled"},
+		},
+	}
+	want := EnclosureHealth{
+		EnclosureName:      "enc1",
+		Status:             "Warning",
+		FansNotOK:          []int{2},
+		PowerSuppliesNotOK: []int{2},
+		InterconnectsNotOK: []int{2, 3},
+		ManagersNotOK:      []int{1, 3},
+	}
+	h := enc.Health()
+	if !reflect.DeepEqual(h, want) {
+		t.Errorf("Health():\n got %+v\nwant %+v", h, want)
+	}
+	if h.OK() {
+		t.Errorf("degraded enclosure reported OK")
+	}
+
+	ok := &Enclosure{Name: "enc2", Status: "OK", ManagerBays: []EnclosureManagerBay{{BayNumber: 1, DevicePresence: "Present", BayPowerState: "On", Status: "OK"}}}
+	if h := ok.Health(); !h.OK() {
+		t.Errorf("healthy enclosure: %+v", h)
+	}
+}
+
+func TestManagerReachability(t *testing.T) {
+	tests := []struct {
+		bay  EnclosureManagerBay
+		want string
+	}{
+		{EnclosureManagerBay{BayPowerState: "On", LinkPortState: "Linked", MgmtPortState: "Linked"}, "Reachable"},
+		{EnclosureManagerBay{BayPowerState: "On", MgmtPortState: "Linked"}, "Reachable"},
+		{EnclosureManagerBay{LinkPortState: "Linked"}, "Reachable"},
+		{EnclosureManagerBay{BayPowerState: "On", LinkPortState: "Linked", MgmtPortState: "Unlinked"}, "Unreachable"},
+		{EnclosureManagerBay{BayPowerState: "On", LinkPortState: "Unlinked"}, "Unreachable"},
+		{EnclosureManagerBay{BayPowerState: "Off", IPAddress: "10.0.0.10", MgmtPortState: "Linked"}, "Unreachable"},
+		{EnclosureManagerBay{BayPowerState: "Off"}, "Unreachable"},
+		{EnclosureManagerBay{BayPowerState: "On", IPAddress: "10.0.0.10", FqdnHostName: "oa1.example.com"}, "Unknown"},
+		{EnclosureManagerBay{}, "Unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.bay.Reachability(); got != tt.want {
+			t.Errorf("%+v: got %s, want %s", tt.bay, got, tt.want)
+		}
+	}
+}
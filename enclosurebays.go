@@ -0,0 +1,92 @@
+package oneview
+
+import "sort"
+
+//Форм-факторы профилей, которые может принять свободный отсек корзины
+const (
+	FormFactorHalfHeight           = "HalfHeight"
+	FormFactorFullHeight           = "FullHeight"
+	FormFactorHalfHeightDoubleWide = "HalfHeightDoubleWide"
+	FormFactorFullHeightDoubleWide = "FullHeightDoubleWide"
+)
+
+//FreeDeviceBay  - свободный отсек корзины и форм-факторы, которые в него можно установить
+type FreeDeviceBay struct {
+	BayNumber   int      `json:"BayNumber"`
+	FormFactors []string `json:"FormFactors"`
+}
+
+//EnclosureBayOccupancy  - занятость отсеков корзины
+type EnclosureBayOccupancy struct {
+	EnclosureName       string          `json:"EnclosureName"`
+	SerialNumber        string          `json:"SerialNumber"`
+	EnclosureModel      string          `json:"EnclosureModel"`
+	DeviceBayCount      int             `json:"DeviceBayCount"`      //всего отсеков
+	Occupied            int             `json:"Occupied"`            //отсеков с установленным оборудованием
+	EmptyBays           []FreeDeviceBay `json:"EmptyBays"`           //свободные отсеки
+	UnassignedBays      []int           `json:"UnassignedBays"`      //отсеки с оборудованием без серверного профиля
+	AcceptedFormFactors []string        `json:"AcceptedFormFactors"` //форм-факторы, которые корзина еще может принять
+}
+
+//freeBayFormFactors  - форм-факторы, доступные в отсеке
+func freeBayFormFactors(bay EnclosureDeviceBay) []string {
+	list := make([]string, 0, 4)
+	if bay.AvailableForHalfHeightProfile {
+		list = append(list, FormFactorHalfHeight)
+	}
+	if bay.AvailableForFullHeightProfile {
+		list = append(list, FormFactorFullHeight)
+	}
+	if bay.AvailableForHalfHeightDoubleWideProfile {
+		list = append(list, FormFactorHalfHeightDoubleWide)
+	}
+	if bay.AvailableForFullHeightDoubleWideProfile {
+		list = append(list, FormFactorFullHeightDoubleWide)
+	}
+	return list
+}
+
+//BayOccupancy  - занятость отсеков корзины: свободные отсеки и отсеки с оборудованием без профиля
+func (enc *Enclosure) BayOccupancy() EnclosureBayOccupancy {
+	occ := EnclosureBayOccupancy{
+		EnclosureName:       enc.Name,
+		SerialNumber:        enc.SerialNumber,
+		EnclosureModel:      enc.EnclosureModel,
+		DeviceBayCount:      enc.DeviceBayCount,
+		EmptyBays:           make([]FreeDeviceBay, 0),
+		UnassignedBays:      make([]int, 0),
+		AcceptedFormFactors: make([]string, 0),
+	}
+	accepted := make(map[string]bool)
+	for _, bay := range enc.DeviceBays {
+		switch bay.DevicePresence {
+		case "Absent":
+			free := FreeDeviceBay{BayNumber: bay.BayNumber, FormFactors: freeBayFormFactors(bay)}
+			for _, ff := range free.FormFactors {
+				accepted[ff] = true
+			}
+			occ.EmptyBays = append(occ.EmptyBays, free)
+		case "Present":
+			occ.Occupied++
+			if bay.ProfileURI == "" {
+				occ.UnassignedBays = append(occ.UnassignedBays, bay.BayNumber)
+			}
+		}
+	}
+	for _, ff := range []string{FormFactorHalfHeight, FormFactorFullHeight, FormFactorHalfHeightDoubleWide, FormFactorFullHeightDoubleWide} {
+		if accepted[ff] {
+			occ.AcceptedFormFactors = append(occ.AcceptedFormFactors, ff)
+		}
+	}
+	return occ
+}
+
+//BayOccupancyReport  - занятость отсеков всех корзин, отсортированная по имени корзины
+func (infra *OVInfrastructure) BayOccupancyReport() []EnclosureBayOccupancy {
+	list := make([]EnclosureBayOccupancy, 0)
+	for _, enc := range infra.enclosureList() {
+		list = append(list, enc.BayOccupancy())
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].EnclosureName < list[j].EnclosureName })
+	return list
+}
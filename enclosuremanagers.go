@@ -0,0 +1,74 @@
+package oneview
+
+import "sort"
+
+//EnclosureManagers  - менеджеры корзины (Onboard Administrator) и найденные проблемы конфигурации
+type EnclosureManagers struct {
+	EnclosureName        string               `json:"EnclosureName"`
+	SerialNumber         string               `json:"SerialNumber"`
+	Active               *EnclosureManagerBay `json:"Active"`               //активный менеджер, nil если не найден
+	Standby              *EnclosureManagerBay `json:"Standby"`              //резервный менеджер, nil если не найден
+	ActiveOaPreferredIP  string               `json:"ActiveOaPreferredIP"`  //предпочтительный адрес активного менеджера
+	StandbyOaPreferredIP string               `json:"StandbyOaPreferredIP"` //предпочтительный адрес резервного менеджера
+	NoStandby            bool                 `json:"NoStandby"`            //резервный менеджер отсутствует
+	FirmwareMismatch     bool                 `json:"FirmwareMismatch"`     //версии прошивок активного и резервного менеджеров различаются
+	PreferredIPMismatch  bool                 `json:"PreferredIPMismatch"`  //фактический адрес менеджера отличается от предпочтительного
+	ActiveReachability   string               `json:"ActiveReachability"`   //доступность активного менеджера, "" если не найден
+	StandbyReachability  string               `json:"StandbyReachability"`  //доступность резервного менеджера, "" если не найден
+	Unreachable          bool                 `json:"Unreachable"`          //установленный менеджер недоступен
+}
+
+//Problem  - у корзины найдена хотя бы одна проблема конфигурации или доступности менеджеров
+func (m EnclosureManagers) Problem() bool {
+	return m.NoStandby || m.FirmwareMismatch || m.PreferredIPMismatch || m.Unreachable
+}
+
+//Managers  - активный и резервный менеджеры корзины с проверкой конфигурации
+func (enc *Enclosure) Managers() EnclosureManagers {
+	m := EnclosureManagers{
+		EnclosureName:        enc.Name,
+		SerialNumber:         enc.SerialNumber,
+		ActiveOaPreferredIP:  enc.ActiveOaPreferredIP,
+		StandbyOaPreferredIP: enc.StandbyOaPreferredIP,
+	}
+	for i := range enc.ManagerBays {
+		bay := &enc.ManagerBays[i]
+		if bay.DevicePresence == "Absent" {
+			continue
+		}
+		switch bay.Role {
+		case "Active":
+			m.Active = bay
+		case "Standby":
+			m.Standby = bay
+		}
+	}
+	m.NoStandby = m.Standby == nil
+	if m.Active != nil {
+		m.ActiveReachability = m.Active.Reachability()
+	}
+	if m.Standby != nil {
+		m.StandbyReachability = m.Standby.Reachability()
+	}
+	m.Unreachable = m.ActiveReachability == "Unreachable" || m.StandbyReachability == "Unreachable"
+	if m.Active != nil && m.Standby != nil {
+		m.FirmwareMismatch = CompareFirmwareVersions(m.Active.FwVersion, m.Standby.FwVersion) != 0
+	}
+	if m.Active != nil && m.ActiveOaPreferredIP != "" && m.Active.IPAddress != m.ActiveOaPreferredIP {
+		m.PreferredIPMismatch = true
+	}
+	if m.Standby != nil && m.StandbyOaPreferredIP != "" && m.Standby.IPAddress != m.StandbyOaPreferredIP {
+		m.PreferredIPMismatch = true
+	}
+	return m
+}
+
+//ManagerInventory  - менеджеры всех корзин, отсортированные по имени корзины
+func (infra *OVInfrastructure) ManagerInventory() []EnclosureManagers {
+	list := make([]EnclosureManagers, 0)
+	for _, enc := range infra.enclosureList() {
+		list = append(list, enc.Managers())
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].EnclosureName < list[j].EnclosureName })
+	return list
+}
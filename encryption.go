@@ -0,0 +1,139 @@
+package oneview
+
+//EncryptionState  - состояние шифрования данных
+type EncryptionState string
+
+const (
+	EncryptionStateEncrypted      EncryptionState = "Encrypted"      //данные зашифрованы, тесты пройдены
+	EncryptionStateSelfTestFailed EncryptionState = "SelfTestFailed" //шифрование включено, но тесты контроллера не пройдены
+	EncryptionStateUnencrypted    EncryptionState = "Unencrypted"    //шифрование не используется
+	EncryptionStateUnknown        EncryptionState = "Unknown"        //нет данных о хранилищах сервера
+)
+
+//RegulatedTag  - метка серверов, к которым применяется политика шифрования по умолчанию
+const RegulatedTag = "regulated"
+
+//EncryptionRecord  - состояние шифрования контроллера или логического диска,
+//для сервера без данных о хранилищах - одна запись без контроллера в состоянии EncryptionStateUnknown
+type EncryptionRecord struct {
+	ServerSerialNumber string          `json:"ServerSerialNumber"` //серийный номер сервера
+	ServerName         string          `json:"ServerName"`         //имя сервера в OneView
+	Controller         string          `json:"Controller"`         //расположение контроллера "Slot 3"
+	ControllerModel    string          `json:"ControllerModel"`    //модель контроллера
+	LogicalDriveNumber int             `json:"LogicalDriveNumber"` //номер логического диска, 0 для записи по контроллеру
+	State              EncryptionState `json:"State"`              //состояние шифрования
+	Regulated          bool            `json:"Regulated"`          //сервер попадает под политику
+}
+
+//Compliant  - запись соответствует политике (зашифровано или сервер не попадает под политику),
+//сервер под политикой без данных о хранилищах не соответствует ей
+func (r EncryptionRecord) Compliant() bool {
+	return !r.Regulated || r.State == EncryptionStateEncrypted
+}
+
+//EncryptionReport  - отчет о шифровании данных на всех контроллерах и логических дисках
+type EncryptionReport struct {
+	Records []EncryptionRecord `json:"Records"`
+}
+
+//Violations  - записи по серверам под политикой, не соответствующие ей
+func (r EncryptionReport) Violations() []EncryptionRecord {
+	violations := make([]EncryptionRecord, 0)
+	for _, rec := range r.Records {
+		if !rec.Compliant() {
+			violations = append(violations, rec)
+		}
+	}
+	return violations
+}
+
+//ExitCode  - результат проверки политики, 0 если все серверы под политикой соответствуют ей, иначе 1
+func (r EncryptionReport) ExitCode() int {
+	if len(r.Violations()) > 0 {
+		return 1
+	}
+	return 0
+}
+
+//selfEncrypted  - самошифрующийся диск с включенным шифрованием
+func selfEncrypted(encrypted bool, status string) bool {
+	switch status {
+	case "Unlocked", "Locked", "Foreign":
+		return true
+	}
+	return encrypted
+}
+
+//controllerEncryptionState  - состояние шифрования на контроллере: шифрование контроллера
+//или самошифрующиеся диски (все физические диски контроллера)
+func controllerEncryptionState(ls LocalStorage) EncryptionState {
+	if !ls.EncryptionEnabled {
+		if len(ls.PhysicalDrives) == 0 {
+			return EncryptionStateUnencrypted
+		}
+		for _, d := range ls.PhysicalDrives {
+			if !selfEncrypted(d.EncryptedDrive, d.EncryptionStatus) {
+				return EncryptionStateUnencrypted
+			}
+		}
+		return EncryptionStateEncrypted
+	}
+	if !ls.EncryptionCspTestPassed || !ls.EncryptionSelfTestPassed {
+		return EncryptionStateSelfTestFailed
+	}
+	return EncryptionStateEncrypted
+}
+
+//logicalDriveEncryptionState  - состояние шифрования логического диска с учетом контроллера и самошифрующихся дисков
+func logicalDriveEncryptionState(ctrl EncryptionState, ld LocalLogicalDrive) EncryptionState {
+	if ld.LogicalDriveEncryption {
+		if ctrl == EncryptionStateSelfTestFailed {
+			return EncryptionStateSelfTestFailed
+		}
+		return EncryptionStateEncrypted
+	}
+	if len(ld.DataDrives) == 0 {
+		return EncryptionStateUnencrypted
+	}
+	for _, dd := range ld.DataDrives {
+		if !selfEncrypted(dd.EncryptedDrive, dd.EncryptionStatus) {
+			return EncryptionStateUnencrypted
+		}
+	}
+	return EncryptionStateEncrypted
+}
+
+//EncryptionComplianceReport  - отчет о шифровании данных по всем серверам, серверы с меткой regulatedTag попадают под политику
+func (infra *OVInfrastructure) EncryptionComplianceReport(regulatedTag string) EncryptionReport {
+	report := EncryptionReport{Records: make([]EncryptionRecord, 0)}
+	for _, srv := range infra.Servers {
+		regulated := srv.HasTag(regulatedTag)
+		if len(srv.Storage.Data) == 0 {
+			report.Records = append(report.Records, EncryptionRecord{
+				ServerSerialNumber: srv.Base.SerialNumber.String(),
+				ServerName:         srv.Base.Name,
+				State:              EncryptionStateUnknown,
+				Regulated:          regulated,
+			})
+			continue
+		}
+		for _, ls := range srv.Storage.Data {
+			rec := EncryptionRecord{
+				ServerSerialNumber: srv.Base.SerialNumber.String(),
+				ServerName:         srv.Base.Name,
+				Controller:         ls.Location,
+				ControllerModel:    ls.Model,
+				State:              controllerEncryptionState(ls),
+				Regulated:          regulated,
+			}
+			report.Records = append(report.Records, rec)
+			for _, ld := range ls.LogicalDrives {
+				ldRec := rec
+				ldRec.LogicalDriveNumber = ld.LogicalDriveNumber
+				ldRec.State = logicalDriveEncryptionState(rec.State, ld)
+				report.Records = append(report.Records, ldRec)
+			}
+		}
+	}
+	return report
+}
@@ -0,0 +1,339 @@
+package oneview
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//ExportColumn  - столбец выгрузки: заголовок и путь к полю строки (синтаксис путей языка запросов)
+type ExportColumn struct {
+	Header string `json:"Header"`
+	Path   string `json:"Path"`
+}
+
+//ServerExportColumns  - столбцы таблицы серверов по умолчанию, поля Base доступны без префикса
+var ServerExportColumns = []ExportColumn{
+	{"Endpoint", "endpoint"},
+	{"Site", "site"},
+	{"Name", "name"},
+	{"SerialNumber", "serialNumber"},
+	{"UUID", "uuid"},
+	{"Model", "model"},
+	{"Generation", "generation"},
+	{"ProcessorType", "processorType"},
+	{"ProcessorCount", "processorCount"},
+	{"ProcessorCoreCount", "processorCoreCount"},
+	{"MemoryMb", "memoryMb"},
+	{"PowerState", "powerState"},
+	{"Health", "status"},
+	{"RomVersion", "romVersion"},
+	{"IloFirmware", "mpFirmwareVersion"},
+	{"Tags", "tags"},
+}
+
+//MemoryExportColumns  - столбцы таблицы модулей памяти по умолчанию
+var MemoryExportColumns = []ExportColumn{
+	{"ServerSerial", "serverSerial"},
+	{"ServerName", "serverName"},
+	{"Locator", "DeviceLocator"},
+	{"Socket", "MemoryLocation.Socket"},
+	{"Slot", "MemoryLocation.Slot"},
+	{"CapacityMiB", "CapacityMiB"},
+	{"Type", "MemoryDeviceType"},
+	{"ModuleType", "BaseModuleType"},
+	{"SpeedMhz", "OperatingSpeedMhz"},
+	{"Manufacturer", "Manufacturer"},
+	{"PartNumber", "PartNumber"},
+	{"Health", "Status.Health"},
+	{"DIMMStatus", "Oem.Hpe.DIMMStatus"},
+}
+
+//DriveExportColumns  - столбцы таблицы физических дисков по умолчанию
+var DriveExportColumns = []ExportColumn{
+	{"ServerSerial", "serverSerial"},
+	{"ServerName", "serverName"},
+	{"Controller", "controller"},
+	{"Location", "Location"},
+	{"Model", "Model"},
+	{"SerialNumber", "SerialNumber"},
+	{"MediaType", "MediaType"},
+	{"CapacityMiB", "CapacityMiB"},
+	{"Firmware", "FirmwareVersion.Current.VersionString"},
+	{"Encrypted", "EncryptedDrive"},
+	{"Health", "Status.Health"},
+}
+
+//ControllerExportColumns  - столбцы таблицы контроллеров по умолчанию
+var ControllerExportColumns = []ExportColumn{
+	{"ServerSerial", "serverSerial"},
+	{"ServerName", "serverName"},
+	{"Name", "Name"},
+	{"Location", "Location"},
+	{"Model", "Model"},
+	{"SerialNumber", "SerialNumber"},
+	{"Firmware", "FirmwareVersion.Current.VersionString"},
+	{"CacheMemorySizeMiB", "CacheMemorySizeMiB"},
+	{"CacheHealth", "CacheModuleStatus.Health"},
+	{"Battery", "BackupPowerSourceStatus"},
+	{"EncryptionEnabled", "EncryptionEnabled"},
+	{"Health", "Status.Health"},
+}
+
+//ExportOptions  - состав столбцов таблиц выгрузки, nil - столбцы по умолчанию
+type ExportOptions struct {
+	ServerColumns     []ExportColumn
+	MemoryColumns     []ExportColumn
+	DriveColumns      []ExportColumn
+	ControllerColumns []ExportColumn
+}
+
+//ExportTable  - таблица выгрузки: имя (лист XLSX или файл CSV), заголовки и строки
+type ExportTable struct {
+	Name    string
+	Headers []string
+	Rows    [][]string
+}
+
+//toDocument  - структура в виде map для вычисления путей к полям
+func toDocument(v interface{}) map[string]interface{} {
+	doc := make(map[string]interface{})
+	data, err := json.Marshal(v)
+	if err == nil {
+		json.Unmarshal(data, &doc)
+	}
+	return doc
+}
+
+//newExportTable  - таблица с заголовками столбцов
+func newExportTable(name string, columns []ExportColumn) *ExportTable {
+	t := &ExportTable{Name: name, Headers: make([]string, 0, len(columns)), Rows: make([][]string, 0)}
+	for _, col := range columns {
+		t.Headers = append(t.Headers, col.Header)
+	}
+	return t
+}
+
+//addRow  - строка таблицы из документа, несколько значений поля объединяются через "; "
+func (t *ExportTable) addRow(doc interface{}, columns []ExportColumn) {
+	row := make([]string, 0, len(columns))
+	for _, col := range columns {
+		values := make([]string, 0)
+		for _, v := range resolveQueryPath(doc, strings.Split(col.Path, ".")) {
+			if v != nil {
+				values = append(values, scalarString(v))
+			}
+		}
+		row = append(row, strings.Join(values, "; "))
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+//columnsOrDefault  - заданные столбцы или столбцы по умолчанию
+func columnsOrDefault(columns []ExportColumn, def []ExportColumn) []ExportColumn {
+	if len(columns) == 0 {
+		return def
+	}
+	return columns
+}
+
+//ExportServerTables  - таблицы servers, memory, drives и controllers по списку серверов,
+//строки модулей памяти, дисков и контроллеров дополнены полями serverSerial, serverName, endpoint, а диски - controller
+func ExportServerTables(servers []*ServerHardware, opt ExportOptions) []ExportTable {
+	serverCols := columnsOrDefault(opt.ServerColumns, ServerExportColumns)
+	memoryCols := columnsOrDefault(opt.MemoryColumns, MemoryExportColumns)
+	driveCols := columnsOrDefault(opt.DriveColumns, DriveExportColumns)
+	controllerCols := columnsOrDefault(opt.ControllerColumns, ControllerExportColumns)
+
+	serverTable := newExportTable("servers", serverCols)
+	memoryTable := newExportTable("memory", memoryCols)
+	driveTable := newExportTable("drives", driveCols)
+	controllerTable := newExportTable("controllers", controllerCols)
+
+	for _, srv := range servers {
+		serverTable.addRow(serverDocument(srv), serverCols)
+
+		owner := func(v interface{}) map[string]interface{} {
+			doc := toDocument(v)
+			doc["serverSerial"] = srv.Base.SerialNumber.String()
+			doc["serverName"] = srv.Base.Name
+			doc["endpoint"] = srv.Endpoint
+			return doc
+		}
+		for _, m := range srv.Memory.Data {
+			memoryTable.addRow(owner(m), memoryCols)
+		}
+		for _, ctrl := range srv.Storage.Data {
+			for _, d := range ctrl.PhysicalDrives {
+				doc := owner(d)
+				doc["controller"] = ctrl.Name
+				driveTable.addRow(doc, driveCols)
+			}
+			controllerTable.addRow(owner(ctrl), controllerCols)
+		}
+	}
+	return []ExportTable{*serverTable, *memoryTable, *driveTable, *controllerTable}
+}
+
+//ExportTables  - таблицы выгрузки по всем загруженным серверам
+func (infra *OVInfrastructure) ExportTables(opt ExportOptions) []ExportTable {
+	servers := make([]*ServerHardware, len(infra.Servers))
+	copy(servers, infra.Servers)
+	sortServers(servers)
+	return ExportServerTables(servers, opt)
+}
+
+//WriteCSV  - запись таблицы в формате CSV с заголовком
+func WriteCSV(w io.Writer, t ExportTable) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Headers); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+//ExportCSV  - запись таблиц выгрузки в каталог dir, файл <имя таблицы>.csv на каждую таблицу
+func (infra *OVInfrastructure) ExportCSV(dir string, opt ExportOptions) error {
+	for _, t := range infra.ExportTables(opt) {
+		f, err := os.Create(filepath.Join(dir, t.Name+".csv"))
+		if err != nil {
+			return err
+		}
+		err = WriteCSV(f, t)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//xlsxColumn  - буквенное обозначение столбца листа: 0 - "A", 26 - "AA"
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+//xlsxEscape  - экранирование текста для XML
+func xlsxEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+//xlsxNumber  - значения, записываемые в лист числом (без ведущих нулей, чтобы не терять серийные номера)
+var xlsxNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]{0,14})(\.[0-9]+)?$`)
+
+//writeXLSXSheet  - содержимое листа, первая строка - заголовки
+func writeXLSXSheet(w io.Writer, t ExportTable) error {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	rows := append([][]string{t.Headers}, t.Rows...)
+	for r, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := fmt.Sprintf("%s%d", xlsxColumn(c), r+1)
+			switch {
+			case value == "":
+			case r > 0 && xlsxNumber.MatchString(value):
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, value)
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xlsxEscape(value))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//xlsxSheetName  - имя листа: не более 31 символа, без недопустимых символов
+func xlsxSheetName(name string) string {
+	name = strings.NewReplacer(":", "_", "\\", "_", "/", "_", "?", "_", "*", "_", "[", "_", "]", "_").Replace(name)
+	if r := []rune(name); len(r) > 31 {
+		name = string(r[:31])
+	}
+	if name == "" {
+		name = "Sheet"
+	}
+	return name
+}
+
+//WriteXLSX  - запись таблиц в книгу XLSX, по листу на таблицу
+func WriteXLSX(w io.Writer, tables []ExportTable) error {
+	zw := zip.NewWriter(w)
+	files := make(map[string]string)
+	order := []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels"}
+
+	var types, sheets, rels strings.Builder
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, t := range tables {
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&sheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(xlsxSheetName(t.Name)), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	types.WriteString(`</Types>`)
+	rels.WriteString(`</Relationships>`)
+
+	files["[Content_Types].xml"] = types.String()
+	files["_rels/.rels"] = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	files["xl/workbook.xml"] = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + sheets.String() + `</sheets></workbook>`
+	files["xl/_rels/workbook.xml.rels"] = rels.String()
+
+	for _, name := range order {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, files[name]); err != nil {
+			return err
+		}
+	}
+	for i, t := range tables {
+		f, err := zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1))
+		if err != nil {
+			return err
+		}
+		if err := writeXLSXSheet(f, t); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+//ExportXLSX  - запись таблиц выгрузки в книгу XLSX path
+func (infra *OVInfrastructure) ExportXLSX(path string, opt ExportOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = WriteXLSX(f, infra.ExportTables(opt))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
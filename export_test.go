@@ -0,0 +1,124 @@
+package oneview
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestXLSXColumn(t *testing.T) {
+	tests := []struct {
+		i    int
+		want string
+	}{
+		{0, "A"},
+		{1, "B"},
+		{25, "Z"},
+		{26, "AA"},
+		{27, "AB"},
+		{51, "AZ"},
+		{52, "BA"},
+		{701, "ZZ"},
+		{702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := xlsxColumn(tt.i); got != tt.want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", tt.i, got, tt.want)
+		}
+	}
+}
+
+func TestXLSXSheetName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"servers", "servers"},
+		{"a/b:c?d*e[f]g\\h", "a_b_c_d_e_f_g_h"},
+		{"", "Sheet"},
+		{strings.Repeat("д", 40), strings.Repeat("д", 31)},
+	}
+	for _, tt := range tests {
+		if got := xlsxSheetName(tt.name); got != tt.want {
+			t.Errorf("xlsxSheetName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+//readZipFile  - содержимое файла архива XLSX
+func readZipFile(t *testing.T, zr *zip.Reader, name string) string {
+	t.Helper()
+	for _, f := range zr.File {
+		if f.Name == name {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rc.Close()
+			data, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(data)
+		}
+	}
+	t.Fatalf("%s not found in workbook", name)
+	return ""
+}
+
+func TestWriteXLSX(t *testing.T) {
+	headers := make([]string, 28)
+	row := make([]string, 28)
+	for i := range headers {
+		headers[i] = "col" + strconv.Itoa(i+1)
+	}
+	row[0] = "0012345"   //серийный номер с ведущим нулем остается строкой
+	row[1] = "262144"    //число
+	row[2] = "a < b & c" //экранирование
+	row[27] = "last"     //столбец AB
+	tables := []ExportTable{
+		{Name: "servers", Headers: headers, Rows: [][]string{row}},
+		{Name: "drives/1", Headers: []string{"Serial"}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, tables); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workbook := readZipFile(t, zr, "xl/workbook.xml")
+	if !strings.Contains(workbook, `<sheet name="servers" sheetId="1" r:id="rId1"/>`) ||
+		!strings.Contains(workbook, `<sheet name="drives_1" sheetId="2" r:id="rId2"/>`) {
+		t.Errorf("workbook sheets: %s", workbook)
+	}
+	readZipFile(t, zr, "[Content_Types].xml")
+	readZipFile(t, zr, "xl/worksheets/sheet2.xml")
+
+	sheet := readZipFile(t, zr, "xl/worksheets/sheet1.xml")
+	var doc struct{}
+	if err := xml.Unmarshal([]byte(sheet), &doc); err != nil {
+		t.Fatalf("sheet1 is not valid XML: %v", err)
+	}
+	for _, want := range []string{
+		`<c r="AB1" t="inlineStr"><is><t xml:space="preserve">col28</t></is></c>`,
+		`<c r="A2" t="inlineStr"><is><t xml:space="preserve">0012345</t></is></c>`,
+		`<c r="B2"><v>262144</v></c>`,
+		`<t xml:space="preserve">a &lt; b &amp; c</t>`,
+		`<c r="AB2" t="inlineStr"><is><t xml:space="preserve">last</t></is></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet1 does not contain %s", want)
+		}
+	}
+	if strings.Contains(sheet, `r="D2"`) {
+		t.Errorf("empty cell D2 written")
+	}
+}
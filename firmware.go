@@ -0,0 +1,216 @@
+package oneview
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//FirmwareComponentType  - тип компонента с прошивкой
+type FirmwareComponentType string
+
+const (
+	FirmwareServerROM         FirmwareComponentType = "ServerROM"         //системный ROM сервера
+	FirmwareILO               FirmwareComponentType = "iLO"               //iLO сервера
+	FirmwareStorageController FirmwareComponentType = "StorageController" //контроллер локального хранилища
+	FirmwarePhysicalDrive     FirmwareComponentType = "PhysicalDrive"     //физический диск
+	FirmwareStorageEnclosure  FirmwareComponentType = "StorageEnclosure"  //корзина дисков контроллера
+	FirmwareEnclosureManager  FirmwareComponentType = "EnclosureManager"  //Onboard Administrator / менеджер корзины
+	FirmwareInterconnect      FirmwareComponentType = "Interconnect"      //коммутационный модуль
+)
+
+//FirmwareRecord  - запись инвентаризации прошивок
+type FirmwareRecord struct {
+	ComponentType FirmwareComponentType `json:"ComponentType"` //тип компонента
+	Model         string                `json:"Model"`         //модель компонента
+	Version       string                `json:"Version"`       //нормализованная версия
+	RawVersion    string                `json:"RawVersion"`    //версия в том виде, как ее вернул OneView
+	Location      string                `json:"Location"`      //расположение "CZ28510H7T/Slot 3/1I:1:7"
+	SerialNumber  string                `json:"SerialNumber"`  //серийный номер сервера или корзины, к которым относится компонент
+}
+
+var (
+	reFirmwareV    = regexp.MustCompile(`\bv(\d+(\.\d+)*)\b`)
+	reFirmwareDate = regexp.MustCompile(`\b(\d{2})/(\d{2})/(\d{4})\b`)
+	reFirmwareNum  = regexp.MustCompile(`^\d+(\.\d+)*`)
+)
+
+//NormalizeFirmwareVersion  - приведение строки версии к сравнимому виду
+//"U30 v2.50 (09/21/2020)" -> "2.50", "I36 11/03/2014" -> "2014.11.03", "2.03 Nov 07 2014" -> "2.03"
+func NormalizeFirmwareVersion(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if m := reFirmwareV.FindStringSubmatch(raw); m != nil {
+		return m[1]
+	}
+	if m := reFirmwareNum.FindString(raw); m != "" {
+		return m
+	}
+	if m := reFirmwareDate.FindStringSubmatch(raw); m != nil {
+		return m[3] + "." + m[1] + "." + m[2]
+	}
+	return raw
+}
+
+//splitVersion  - разбиение версии на числовые и буквенные части
+func splitVersion(v string) []string {
+	parts := make([]string, 0)
+	cur := ""
+	digit := false
+	for _, r := range v {
+		isDigit := r >= '0' && r <= '9'
+		isAlpha := !isDigit && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+		if !isDigit && !isAlpha {
+			if cur != "" {
+				parts = append(parts, cur)
+			}
+			cur = ""
+			continue
+		}
+		if cur != "" && isDigit != digit {
+			parts = append(parts, cur)
+			cur = ""
+		}
+		cur += string(r)
+		digit = isDigit
+	}
+	if cur != "" {
+		parts = append(parts, cur)
+	}
+	return parts
+}
+
+//CompareFirmwareVersions  - сравнение нормализованных версий, -1 если a < b, 0 если равны, 1 если a > b
+func CompareFirmwareVersions(a string, b string) int {
+	pa := splitVersion(NormalizeFirmwareVersion(a))
+	pb := splitVersion(NormalizeFirmwareVersion(b))
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		if i >= len(pa) {
+			return -1
+		}
+		if i >= len(pb) {
+			return 1
+		}
+		na, errA := strconv.Atoi(pa[i])
+		nb, errB := strconv.Atoi(pb[i])
+		if errA == nil && errB == nil {
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+			continue
+		}
+		if c := strings.Compare(strings.ToUpper(pa[i]), strings.ToUpper(pb[i])); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+//newFirmwareRecord  - создание записи с нормализацией версии
+func newFirmwareRecord(t FirmwareComponentType, model string, raw string, location string, sn string) FirmwareRecord {
+	return FirmwareRecord{
+		ComponentType: t,
+		Model:         strings.TrimSpace(model),
+		Version:       NormalizeFirmwareVersion(raw),
+		RawVersion:    raw,
+		Location:      location,
+		SerialNumber:  sn,
+	}
+}
+
+//ServerFirmwareInventory  - прошивки сервера: ROM, iLO, контроллеры, корзины дисков и диски
+func ServerFirmwareInventory(srv *ServerHardware) []FirmwareRecord {
+	sn := srv.Base.SerialNumber.String()
+	list := make([]FirmwareRecord, 0)
+	if srv.Base.RomVersion != "" {
+		list = append(list, newFirmwareRecord(FirmwareServerROM, srv.Base.Model, srv.Base.RomVersion, sn, sn))
+	}
+	if srv.Base.MpFirwareVersion != "" {
+		list = append(list, newFirmwareRecord(FirmwareILO, srv.Base.MpModel, srv.Base.MpFirwareVersion, sn, sn))
+	}
+	for _, ls := range srv.Storage.Data {
+		ctrl := sn + "/" + ls.Location
+		list = append(list, newFirmwareRecord(FirmwareStorageController, ls.Model, ls.FirmwareVersion.Current.VersionString, ctrl, sn))
+		for _, enc := range ls.StorageEnclosures {
+			list = append(list, newFirmwareRecord(FirmwareStorageEnclosure, ls.Model+" storage enclosure", enc.FirmwareVersion.Current.VersionString, ctrl+"/"+enc.Location, sn))
+		}
+		for _, pd := range ls.PhysicalDrives {
+			list = append(list, newFirmwareRecord(FirmwarePhysicalDrive, pd.Model, pd.FirmwareVersion.Current.VersionString, ctrl+"/"+pd.Location, sn))
+		}
+	}
+	return list
+}
+
+//EnclosureFirmwareInventory  - прошивки менеджеров (Onboard Administrator) корзины
+func EnclosureFirmwareInventory(enc *Enclosure) []FirmwareRecord {
+	list := make([]FirmwareRecord, 0)
+	for _, bay := range enc.ManagerBays {
+		if bay.FwVersion == "" {
+			continue
+		}
+		location := enc.Name + "/" + bay.ManagerType + " bay " + strconv.Itoa(bay.BayNumber)
+		list = append(list, newFirmwareRecord(FirmwareEnclosureManager, enc.EnclosureModel+" "+bay.ManagerType, bay.FwVersion, location, enc.SerialNumber))
+	}
+	return list
+}
+
+//FirmwareInventory  - прошивки всех загруженных серверов, корзин и коммутационных модулей
+func (infra *OVInfrastructure) FirmwareInventory() []FirmwareRecord {
+	list := make([]FirmwareRecord, 0)
+	for _, srv := range infra.Servers {
+		list = append(list, ServerFirmwareInventory(srv)...)
+	}
+	for _, enc := range infra.enclosureList() {
+		list = append(list, EnclosureFirmwareInventory(enc)...)
+	}
+	for _, ic := range infra.Interconnects {
+		list = append(list, InterconnectFirmwareInventory(ic)...)
+	}
+	return list
+}
+
+//FirmwareDrift  - распределение версий прошивки для одной модели компонента
+type FirmwareDrift struct {
+	ComponentType FirmwareComponentType `json:"ComponentType"`
+	Model         string                `json:"Model"`
+	Versions      map[string]int        `json:"Versions"` //количество компонентов по версиям
+	Latest        string                `json:"Latest"`   //наибольшая найденная версия
+	Count         int                   `json:"Count"`    //всего компонентов
+}
+
+//Drifted  - у модели установлены разные версии прошивки
+func (d FirmwareDrift) Drifted() bool {
+	return len(d.Versions) > 1
+}
+
+//GroupFirmwareByModel  - группировка записей по типу и модели компонента, отсортированная по типу и модели
+func GroupFirmwareByModel(records []FirmwareRecord) []FirmwareDrift {
+	groups := make(map[string]*FirmwareDrift)
+	for _, rec := range records {
+		key := string(rec.ComponentType) + "\x00" + rec.Model
+		g, ok := groups[key]
+		if !ok {
+			g = &FirmwareDrift{ComponentType: rec.ComponentType, Model: rec.Model, Versions: make(map[string]int)}
+			groups[key] = g
+		}
+		g.Versions[rec.Version]++
+		g.Count++
+		if g.Latest == "" || CompareFirmwareVersions(rec.Version, g.Latest) > 0 {
+			g.Latest = rec.Version
+		}
+	}
+	list := make([]FirmwareDrift, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, *g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].ComponentType != list[j].ComponentType {
+			return list[i].ComponentType < list[j].ComponentType
+		}
+		return list[i].Model < list[j].Model
+	})
+	return list
+}
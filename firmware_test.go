@@ -0,0 +1,54 @@
+package oneview
+
+import "testing"
+
+func TestNormalizeFirmwareVersion(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"U30 v2.50 (09/21/2020)", "2.50"},
+		{"I36 11/03/2014", "2014.11.03"},
+		{"2.03 Nov 07 2014", "2.03"},
+		{"  4.11  ", "4.11"},
+		{"v1.4", "1.4"},
+		{"1.0.0-B12", "1.0.0"},
+		{"HPD8", "HPD8"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeFirmwareVersion(tt.raw); got != tt.want {
+			t.Errorf("NormalizeFirmwareVersion(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestCompareFirmwareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.50", "2.60", -1},
+		{"2.10", "2.9", 1},
+		{"2.50", "2.50", 0},
+		{"1.2", "1.2.0", -1},
+		{"U30 v2.50 (09/21/2020)", "U30 v2.40 (02/02/2021)", 1},
+		{"v2.50", "2.50", 0},
+		{"2.03 Nov 07 2014", "2.03", 0},
+		{"I36 11/03/2014", "I36 02/15/2015", -1},
+		{"HPD8", "HPD9", -1},
+		{"HPD10", "HPD9", 1},
+		{"hpd8", "HPD8", 0},
+		{"4.11", "HPDA", -1},
+		{"", "1.0", -1},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		if got := CompareFirmwareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareFirmwareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := CompareFirmwareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareFirmwareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package oneview
+
+import (
+	"regexp"
+	"sort"
+)
+
+//FleetSummary  - сводные показатели по группе серверов
+type FleetSummary struct {
+	Key                string         `json:"Key"`                //значение поля группировки, "" для общей сводки
+	Servers            int            `json:"Servers"`            //количество серверов
+	Processors         int            `json:"Processors"`         //количество процессоров
+	Cores              int            `json:"Cores"`              //количество ядер
+	MemoryMb           int            `json:"MemoryMb"`           //объем памяти по данным OneView
+	InstalledMemoryMiB int            `json:"InstalledMemoryMiB"` //суммарная емкость установленных модулей памяти
+	MemoryModules      int            `json:"MemoryModules"`      //количество модулей памяти
+	PhysicalDrives     int            `json:"PhysicalDrives"`     //количество дисков
+	RawStorageMiB      float64        `json:"RawStorageMiB"`      //суммарная емкость дисков
+	UsableStorageMiB   float64        `json:"UsableStorageMiB"`   //суммарная емкость логических томов
+	ByModel            map[string]int `json:"ByModel"`            //количество серверов по модели
+	ByGeneration       map[string]int `json:"ByGeneration"`       //количество серверов по поколению "Gen9", "Gen10"
+	ByPowerState       map[string]int `json:"ByPowerState"`       //количество серверов по состоянию питания
+}
+
+//newFleetSummary  - пустая сводка для группы key
+func newFleetSummary(key string) *FleetSummary {
+	return &FleetSummary{
+		Key:          key,
+		ByModel:      make(map[string]int),
+		ByGeneration: make(map[string]int),
+		ByPowerState: make(map[string]int),
+	}
+}
+
+var generationRe = regexp.MustCompile(`(?i)\bGen\s?(\d+)`)
+
+//ServerGeneration  - поколение сервера "Gen9", из Base.Generation или из модели
+func ServerGeneration(srv *ServerHardware) string {
+	if srv.Base.Generation != "" {
+		return srv.Base.Generation
+	}
+	if m := generationRe.FindStringSubmatch(srv.Base.Model); m != nil {
+		return "Gen" + m[1]
+	}
+	return ""
+}
+
+//Add  - учет сервера в сводке
+func (s *FleetSummary) Add(srv *ServerHardware) {
+	s.Servers++
+	s.Processors += srv.Base.ProcessorCount
+	s.Cores += srv.Base.ProcessorCount * srv.Base.ProcessorCoreCount
+	s.MemoryMb += srv.Base.MemoryMb
+	for _, m := range srv.Memory.Data {
+		s.InstalledMemoryMiB += m.CapacityMiB
+		s.MemoryModules++
+	}
+	for _, ctrl := range srv.Storage.Data {
+		for _, d := range ctrl.PhysicalDrives {
+			s.RawStorageMiB += d.CapacityMiB
+			s.PhysicalDrives++
+		}
+		for _, ld := range ctrl.LogicalDrives {
+			s.UsableStorageMiB += ld.CapacityMiB
+		}
+	}
+	s.ByModel[srv.Base.Model]++
+	s.ByGeneration[ServerGeneration(srv)]++
+	s.ByPowerState[srv.Base.PowerState]++
+}
+
+//SummarizeServers  - сводка по списку серверов
+func SummarizeServers(servers []*ServerHardware) FleetSummary {
+	s := newFleetSummary("")
+	for _, srv := range servers {
+		s.Add(srv)
+	}
+	return *s
+}
+
+//GroupSummaries  - сводки по группам серверов с одинаковым значением поля field (путь языка запросов,
+//например "site", "model", "storage.model"), сервер с несколькими значениями поля учитывается в каждой группе,
+//сервер без значения - в группе ""; результат упорядочен по значению
+func GroupSummaries(servers []*ServerHardware, field string) []FleetSummary {
+	groups := make(map[string]*FleetSummary)
+	for _, srv := range servers {
+		values := ServerFieldValues(srv, field)
+		if len(values) == 0 {
+			values = []string{""}
+		}
+		seen := make(map[string]bool)
+		for _, v := range values {
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+			g, ok := groups[v]
+			if !ok {
+				g = newFleetSummary(v)
+				groups[v] = g
+			}
+			g.Add(srv)
+		}
+	}
+	list := make([]FleetSummary, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, *g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Key < list[j].Key
+	})
+	return list
+}
+
+//Summary  - сводка по всем загруженным серверам
+func (infra *OVInfrastructure) Summary() FleetSummary {
+	return SummarizeServers(infra.Servers)
+}
+
+//SummaryBy  - сводки по загруженным серверам, сгруппированные по полю field
+func (infra *OVInfrastructure) SummaryBy(field string) []FleetSummary {
+	return GroupSummaries(infra.Servers, field)
+}
+
+//SummaryByEndpoint  - сводки по точкам подключения
+func (infra *OVInfrastructure) SummaryByEndpoint() []FleetSummary {
+	return infra.SummaryBy("endpoint")
+}
+
+//SummaryBySite  - сводки по площадкам, назначенным SetEndpointSite
+func (infra *OVInfrastructure) SummaryBySite() []FleetSummary {
+	return infra.SummaryBy("site")
+}
@@ -0,0 +1,107 @@
+package oneview
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/HewlettPackard/oneview-golang/ov"
+	"github.com/HewlettPackard/oneview-golang/rest"
+	"github.com/HewlettPackard/oneview-golang/utils"
+)
+
+//ServerRemoteConsoleUrl  - ответ на запрос ссылки на удаленную консоль (hplocons://), используется HTML5 и .NET консолью iLO
+type ServerRemoteConsoleUrl struct {
+	RemoteConsoleUrl string `json:"remoteConsoleUrl"`
+}
+
+//GetServerRemoteConsoleUrl  - запрос ссылки на удаленную консоль iLO сервера по uuid
+func GetServerRemoteConsoleUrl(c *ov.OVClient, uuid utils.Nstring) (string, error) {
+
+	var consoleUrl ServerRemoteConsoleUrl
+	// refresh login
+	c.RefreshLogin()
+	c.SetAuthHeaderOptions(c.GetAuthHeaderMap())
+
+	// rest call
+	data, err := c.RestAPICall(rest.GET, "/rest/server-hardware/"+uuid.String()+"/remoteConsoleUrl", nil)
+	if err != nil {
+		return consoleUrl.RemoteConsoleUrl, err
+	}
+
+	if err := json.Unmarshal([]byte(data), &consoleUrl); err != nil {
+		return consoleUrl.RemoteConsoleUrl, err
+	}
+
+	return consoleUrl.RemoteConsoleUrl, nil
+}
+
+//IloHostName  - имя iLO из MpHostName, для имен вида "ILO-<имя>" возвращается часть после "ILO-"
+func IloHostName(mpHostName string) string {
+	if i := strings.Index(mpHostName, "ILO-"); i >= 0 {
+		return mpHostName[i+len("ILO-"):]
+	}
+	return mpHostName
+}
+
+//IloAccess  - данные для доступа к iLO сервера, ссылки содержат сессионные ключи и не должны записываться в журналы
+type IloAccess struct {
+	SerialNumber               string    `json:"SerialNumber"`
+	IloIP                      string    `json:"IloIP"`                      //адрес iLO
+	HostName                   string    `json:"HostName"`                   //имя iLO
+	SsoURL                     string    `json:"SsoURL"`                     //ссылка для входа в iLO через OneView
+	SsoURLIssued               time.Time `json:"SsoURLIssued"`               //время получения SsoURL
+	JavaRemoteConsoleURL       string    `json:"JavaRemoteConsoleURL"`       //ссылка на Java консоль
+	JavaRemoteConsoleURLIssued time.Time `json:"JavaRemoteConsoleURLIssued"` //время получения JavaRemoteConsoleURL
+	RemoteConsoleURL           string    `json:"RemoteConsoleURL"`           //ссылка на HTML5/.NET консоль (hplocons://)
+	RemoteConsoleURLIssued     time.Time `json:"RemoteConsoleURLIssued"`     //время получения RemoteConsoleURL
+}
+
+//GetIloAccess  - получение адреса, имени и ссылок для доступа к iLO сервера, ссылки запрашиваются при каждом вызове
+func GetIloAccess(c *ov.OVClient, srv *ServerHardware) (IloAccess, error) {
+	var firstErr error
+
+	access := IloAccess{
+		SerialNumber: srv.Base.SerialNumber.String(),
+		IloIP:        srv.Base.GetIloIPAddress(),
+	}
+	if srv.Base.MpHostInfo != nil {
+		access.HostName = IloHostName(srv.Base.MpHostInfo.MpHostName)
+	}
+
+	sso, err := GetServerILOssoUrl(c, srv.Base.UUID)
+	if err == nil {
+		access.SsoURL = sso
+		access.SsoURLIssued = time.Now()
+	} else if firstErr == nil {
+		firstErr = err
+	}
+	java, err := GetServerjavaRemoteConsoleUrl(c, srv.Base.UUID)
+	if err == nil {
+		access.JavaRemoteConsoleURL = java
+		access.JavaRemoteConsoleURLIssued = time.Now()
+	} else if firstErr == nil {
+		firstErr = err
+	}
+	console, err := GetServerRemoteConsoleUrl(c, srv.Base.UUID)
+	if err == nil {
+		access.RemoteConsoleURL = console
+		access.RemoteConsoleURLIssued = time.Now()
+	} else if firstErr == nil {
+		firstErr = err
+	}
+	return access, firstErr
+}
+
+//IloAccessSN  - данные для доступа к iLO сервера с указанным серийным номером
+func (infra *OVInfrastructure) IloAccessSN(sn string) (IloAccess, error) {
+	srv, err := infra.FindServerHardwareSN(sn)
+	if err != nil {
+		return IloAccess{}, err
+	}
+	c, err := infra.clientFor(srv.Endpoint)
+	if err != nil {
+		return IloAccess{}, err
+	}
+	return GetIloAccess(c, srv)
+}
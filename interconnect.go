@@ -0,0 +1,205 @@
+package oneview
+
+import "sort"
+
+//InterconnectPort  - порт коммутационного модуля
+type InterconnectPort struct {
+	PortName         string `json:"portName"`         //"X1", "d1"
+	PortType         string `json:"portType"`         //"Uplink", "Downlink", "Stacking"
+	PortStatus       string `json:"portStatus"`       //"Linked", "Unlinked"
+	PortStatusReason string `json:"portStatusReason"` //причина состояния порта
+	PortHealthStatus string `json:"portHealthStatus"` //"Normal", "Warning", "Critical"
+	Enabled          bool   `json:"enabled"`          //порт включен
+	OperationalSpeed string `json:"operationalSpeed"` //"Speed10G"
+	ConnectorType    string `json:"connectorType"`    //тип трансивера "SFP-SR"
+	URI              string `json:"uri"`
+}
+
+//InterconnectLocationEntry  - элемент расположения модуля {"type": "Bay", "value": "1"}
+type InterconnectLocationEntry struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+//InterconnectLocation  - расположение модуля в корзине
+type InterconnectLocation struct {
+	LocationEntries []InterconnectLocationEntry `json:"locationEntries"`
+}
+
+//Interconnect  - коммутационный модуль (Virtual Connect)
+type Interconnect struct {
+	Type                   string               `json:"type"`
+	URI                    string               `json:"uri"`
+	Name                   string               `json:"name"`
+	Model                  string               `json:"model"`
+	ProductName            string               `json:"productName"`
+	PartNumber             string               `json:"partNumber"`
+	SerialNumber           string               `json:"serialNumber"`
+	FirmwareVersion        string               `json:"firmwareVersion"`
+	Status                 string               `json:"status"` //"OK", "Warning", "Critical"
+	State                  string               `json:"state"`  //"Configured", "Monitored"
+	PowerState             string               `json:"powerState"`
+	UIDState               string               `json:"uidState"`
+	InterconnectIP         string               `json:"interconnectIP"`
+	EnclosureName          string               `json:"enclosureName"`
+	EnclosureURI           string               `json:"enclosureUri"`
+	LogicalInterconnectURI string               `json:"logicalInterconnectUri"`
+	InterconnectLocation   InterconnectLocation `json:"interconnectLocation"`
+	Ports                  []InterconnectPort   `json:"ports"`
+	Endpoint               string               `json:"endpoint,omitempty"` //точка подключения OneView, с которой загружен модуль
+}
+
+//PortsByType  - порты модуля указанного типа
+func (ic *Interconnect) PortsByType(portType string) []InterconnectPort {
+	list := make([]InterconnectPort, 0)
+	for _, p := range ic.Ports {
+		if p.PortType == portType {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+//PortsDown  - включенные порты без соединения
+func (ic *Interconnect) PortsDown() []InterconnectPort {
+	list := make([]InterconnectPort, 0)
+	for _, p := range ic.Ports {
+		if p.Enabled && p.PortStatus != "Linked" {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+//LogicalInterconnect  - логическое объединение коммутационных модулей
+type LogicalInterconnect struct {
+	Type              string   `json:"type"`
+	URI               string   `json:"uri"`
+	Name              string   `json:"name"`
+	Status            string   `json:"status"`
+	State             string   `json:"state"`
+	ConsistencyStatus string   `json:"consistencyStatus"` //"CONSISTENT", "NOT_CONSISTENT"
+	StackingHealth    string   `json:"stackingHealth"`    //"BiConnected", "Connected", "Disconnected"
+	EnclosureURIs     []string `json:"enclosureUris"`
+	Interconnects     []string `json:"interconnects"` //uri модулей
+	Endpoint          string   `json:"endpoint,omitempty"`
+}
+
+//UplinkPortConfigInfo  - порт, входящий в uplink set
+type UplinkPortConfigInfo struct {
+	PortURI      string `json:"portUri"`
+	DesiredSpeed string `json:"desiredSpeed"`
+}
+
+//UplinkSet  - набор внешних портов логического объединения модулей
+type UplinkSet struct {
+	Type                   string                 `json:"type"`
+	URI                    string                 `json:"uri"`
+	Name                   string                 `json:"name"`
+	Status                 string                 `json:"status"`
+	State                  string                 `json:"state"`
+	Reachability           string                 `json:"reachability"` //"Reachable", "RedundantlyReachable"
+	NetworkType            string                 `json:"networkType"`  //"Ethernet", "FibreChannel"
+	ConnectionMode         string                 `json:"connectionMode"`
+	LogicalInterconnectURI string                 `json:"logicalInterconnectUri"`
+	NetworkURIs            []string               `json:"networkUris"`
+	PortConfigInfos        []UplinkPortConfigInfo `json:"portConfigInfos"`
+	Endpoint               string                 `json:"endpoint,omitempty"`
+}
+
+//LoadInterconnects  - загрузка коммутационных модулей, логических объединений и uplink set со всех точек подключения,
+//при повторной загрузке объекты с той же точки подключения и URI заменяются новыми данными
+func (infra *OVInfrastructure) LoadInterconnects() ([]*Interconnect, error) {
+	var err, lastErr error
+
+	infra.Interconnects, err = loadMembers(infra, "/rest/interconnects", infra.Interconnects,
+		func(ic *Interconnect) string { return ic.Endpoint + ic.URI },
+		func(ic *Interconnect, endpoint string) { ic.Endpoint = endpoint })
+	if err != nil {
+		lastErr = err
+	}
+	infra.LogicalInterconnects, err = loadMembers(infra, "/rest/logical-interconnects", infra.LogicalInterconnects,
+		func(li *LogicalInterconnect) string { return li.Endpoint + li.URI },
+		func(li *LogicalInterconnect, endpoint string) { li.Endpoint = endpoint })
+	if err != nil {
+		lastErr = err
+	}
+	infra.UplinkSets, err = loadMembers(infra, "/rest/uplink-sets", infra.UplinkSets,
+		func(us *UplinkSet) string { return us.Endpoint + us.URI },
+		func(us *UplinkSet, endpoint string) { us.Endpoint = endpoint })
+	if err != nil {
+		lastErr = err
+	}
+	infra.linkEnclosures() //состояние модулей учитывается в состоянии корзин
+	return infra.Interconnects, lastErr
+}
+
+//InterconnectBayRecord  - отсек корзины с коммутационным модулем и связанными объектами
+type InterconnectBayRecord struct {
+	EnclosureName       string                   `json:"EnclosureName"`
+	EnclosureSerial     string                   `json:"EnclosureSerial"`
+	Bay                 EnclosureInterconnectBay `json:"Bay"`
+	Interconnect        *Interconnect            `json:"Interconnect"`        //nil если модуль не загружен
+	LogicalInterconnect *LogicalInterconnect     `json:"LogicalInterconnect"` //nil если модуль не входит в объединение
+	UplinkSets          []*UplinkSet             `json:"UplinkSets"`          //uplink set логического объединения
+}
+
+//InterconnectInventory  - коммутационные модули по отсекам всех корзин, отсортированные по корзине и номеру отсека
+func (infra *OVInfrastructure) InterconnectInventory() []InterconnectBayRecord {
+	interconnects := make(map[string]*Interconnect)
+	for _, ic := range infra.Interconnects {
+		interconnects[ic.Endpoint+ic.URI] = ic
+	}
+	logical := make(map[string]*LogicalInterconnect)
+	for _, li := range infra.LogicalInterconnects {
+		logical[li.Endpoint+li.URI] = li
+	}
+	uplinks := make(map[string][]*UplinkSet)
+	for _, us := range infra.UplinkSets {
+		uplinks[us.Endpoint+us.LogicalInterconnectURI] = append(uplinks[us.Endpoint+us.LogicalInterconnectURI], us)
+	}
+
+	list := make([]InterconnectBayRecord, 0)
+	for _, enc := range infra.enclosureList() {
+		for _, bay := range enc.InterconnectBays {
+			if bay.InterconnectURI == "" {
+				continue
+			}
+			rec := InterconnectBayRecord{
+				EnclosureName:   enc.Name,
+				EnclosureSerial: enc.SerialNumber,
+				Bay:             bay,
+				Interconnect:    interconnects[enc.Endpoint+bay.InterconnectURI],
+				UplinkSets:      make([]*UplinkSet, 0),
+			}
+			liURI := bay.LogicalInterconnectURI
+			if liURI == "" && rec.Interconnect != nil {
+				liURI = rec.Interconnect.LogicalInterconnectURI
+			}
+			if liURI != "" {
+				rec.LogicalInterconnect = logical[enc.Endpoint+liURI]
+				rec.UplinkSets = append(rec.UplinkSets, uplinks[enc.Endpoint+liURI]...)
+			}
+			list = append(list, rec)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].EnclosureName != list[j].EnclosureName {
+			return list[i].EnclosureName < list[j].EnclosureName
+		}
+		return list[i].Bay.BayNumber < list[j].Bay.BayNumber
+	})
+	return list
+}
+
+//InterconnectFirmwareInventory  - прошивки коммутационных модулей
+func InterconnectFirmwareInventory(ic *Interconnect) []FirmwareRecord {
+	if ic.FirmwareVersion == "" {
+		return nil
+	}
+	model := ic.Model
+	if model == "" {
+		model = ic.ProductName
+	}
+	return []FirmwareRecord{newFirmwareRecord(FirmwareInterconnect, model, ic.FirmwareVersion, ic.EnclosureName+"/"+ic.Name, ic.SerialNumber)}
+}
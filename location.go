@@ -0,0 +1,174 @@
+package oneview
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//DriveLocation  - разобранное расположение диска или корзины, например "1I:1:7" в формате "ControllerPort:Box:Bay"
+type DriveLocation struct {
+	ControllerPort string `json:"ControllerPort"`       //порт контроллера "1I"
+	Box            int    `json:"Box"`                  //номер корзины
+	Bay            int    `json:"Bay"`                  //номер отсека, 0 для корзины
+	ParseError     string `json:"ParseError,omitempty"` //ошибка разбора строки расположения
+}
+
+//Valid  - расположение разобрано без ошибок
+func (l DriveLocation) Valid() bool {
+	return l.ParseError == "" && (l.ControllerPort != "" || l.Box > 0)
+}
+
+//String  - представление расположения в формате "ControllerPort:Box:Bay"
+func (l DriveLocation) String() string {
+	s := l.ControllerPort + ":" + strconv.Itoa(l.Box)
+	if l.Bay > 0 {
+		s = s + ":" + strconv.Itoa(l.Bay)
+	}
+	return s
+}
+
+//SameBox  - проверка что расположение относится к той же корзине (порт контроллера и номер корзины),
+//неразобранные расположения не относятся ни к одной корзине
+func (l DriveLocation) SameBox(other DriveLocation) bool {
+	return l.Valid() && other.Valid() && l.ControllerPort == other.ControllerPort && l.Box == other.Box
+}
+
+//ParseDriveLocation  - разбор строки расположения Location по формату LocationFormat
+func ParseDriveLocation(location string, format string) (DriveLocation, error) {
+	var loc DriveLocation
+
+	if location == "" {
+		return loc, errors.New("Empty location")
+	}
+	values := strings.Split(location, ":")
+	if format == "" {
+		format = "ControllerPort:Box:Bay"
+		if len(values) == 2 {
+			format = "ControllerPort:Box"
+		}
+	}
+	keys := strings.Split(format, ":")
+	if len(values) != len(keys) {
+		return loc, errors.New("Location " + location + " does not match format " + format)
+	}
+	for i, key := range keys {
+		value := strings.TrimSpace(values[i])
+		switch strings.TrimSpace(key) {
+		case "ControllerPort":
+			loc.ControllerPort = value
+		case "Box":
+			box, err := strconv.Atoi(value)
+			if err != nil {
+				return loc, errors.New("Invalid box number in location " + location)
+			}
+			loc.Box = box
+		case "Bay":
+			bay, err := strconv.Atoi(value)
+			if err != nil {
+				return loc, errors.New("Invalid bay number in location " + location)
+			}
+			loc.Bay = bay
+		}
+	}
+	return loc, nil
+}
+
+//DriveBay  - отсек корзины, Drive заполнен если в отсеке установлен диск
+type DriveBay struct {
+	Location DriveLocation       `json:"Location"`
+	Occupied bool                `json:"Occupied"`
+	Drive    *LocalPhysicalDrive `json:"Drive,omitempty"`
+}
+
+//DriveBayMap  - карта отсеков одной корзины контроллера
+type DriveBayMap struct {
+	Enclosure  LocalStorageEnclosure `json:"Enclosure"`
+	Bays       []DriveBay            `json:"Bays"`
+	OutOfRange []*LocalPhysicalDrive `json:"OutOfRange,omitempty"` //диски корзины с номером отсека вне 1..DriveBayCount
+}
+
+//EmptyBays  - номера свободных отсеков корзины
+func (m DriveBayMap) EmptyBays() []int {
+	empty := make([]int, 0)
+	for _, bay := range m.Bays {
+		if !bay.Occupied {
+			empty = append(empty, bay.Location.Bay)
+		}
+	}
+	return empty
+}
+
+//BayMap  - карта занятых и свободных отсеков по всем корзинам контроллера на основании DriveBayCount,
+//корзины с неразобранным расположением пропускаются
+func (ls *LocalStorage) BayMap() []DriveBayMap {
+	maps := make([]DriveBayMap, 0, len(ls.StorageEnclosures))
+	for _, enc := range ls.StorageEnclosures {
+		if !enc.ParsedLocation.Valid() {
+			continue
+		}
+		m := DriveBayMap{Enclosure: enc, Bays: make([]DriveBay, enc.DriveBayCount)}
+		for i := range m.Bays {
+			m.Bays[i].Location = DriveLocation{ControllerPort: enc.ParsedLocation.ControllerPort, Box: enc.ParsedLocation.Box, Bay: i + 1}
+		}
+		for i := range ls.PhysicalDrives {
+			drive := &ls.PhysicalDrives[i]
+			if !drive.ParsedLocation.SameBox(enc.ParsedLocation) {
+				continue
+			}
+			bay := drive.ParsedLocation.Bay
+			if bay < 1 || bay > len(m.Bays) {
+				m.OutOfRange = append(m.OutOfRange, drive)
+				continue
+			}
+			m.Bays[bay-1].Occupied = true
+			m.Bays[bay-1].Drive = drive
+		}
+		maps = append(maps, m)
+	}
+	return maps
+}
+
+//UnmappedDrives  - диски, не попавшие в карту отсеков: расположение не разобрано или корзина диска не найдена
+func (ls *LocalStorage) UnmappedDrives() []*LocalPhysicalDrive {
+	list := make([]*LocalPhysicalDrive, 0)
+	for i := range ls.PhysicalDrives {
+		drive := &ls.PhysicalDrives[i]
+		found := false
+		for _, enc := range ls.StorageEnclosures {
+			if drive.ParsedLocation.SameBox(enc.ParsedLocation) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, drive)
+		}
+	}
+	return list
+}
+
+//decodeLocation  - разобранное расположение, ошибка разбора сохраняется в ParseError
+func decodeLocation(location string, format string) DriveLocation {
+	loc, err := ParseDriveLocation(location, format)
+	if err != nil {
+		return DriveLocation{ParseError: err.Error()}
+	}
+	return loc
+}
+
+//decodeLocations  - заполнение ParsedLocation у дисков и корзин по строкам Location и LocationFormat
+func (ls *LocalStorage) decodeLocations() {
+	for i := range ls.PhysicalDrives {
+		ls.PhysicalDrives[i].ParsedLocation = decodeLocation(ls.PhysicalDrives[i].Location, ls.PhysicalDrives[i].LocationFormat)
+	}
+	for i := range ls.LogicalDrives {
+		for j := range ls.LogicalDrives[i].DataDrives {
+			drive := &ls.LogicalDrives[i].DataDrives[j]
+			drive.ParsedLocation = decodeLocation(drive.Location, drive.LocationFormat)
+		}
+	}
+	for i := range ls.StorageEnclosures {
+		ls.StorageEnclosures[i].ParsedLocation = decodeLocation(ls.StorageEnclosures[i].Location, ls.StorageEnclosures[i].LocationFormat)
+	}
+}
@@ -0,0 +1,92 @@
+package oneview
+
+import "testing"
+
+func TestParseDriveLocation(t *testing.T) {
+	tests := []struct {
+		location string
+		format   string
+		want     DriveLocation
+		err      bool
+	}{
+		{"1I:1:7", "ControllerPort:Box:Bay", DriveLocation{ControllerPort: "1I", Box: 1, Bay: 7}, false},
+		{"2I:3:12", "", DriveLocation{ControllerPort: "2I", Box: 3, Bay: 12}, false},
+		{"1I:1", "", DriveLocation{ControllerPort: "1I", Box: 1}, false},
+		{"1I:1", "ControllerPort:Box", DriveLocation{ControllerPort: "1I", Box: 1}, false},
+		{" 1I : 2 : 3 ", "ControllerPort : Box : Bay", DriveLocation{ControllerPort: "1I", Box: 2, Bay: 3}, false},
+		{"1:4", "Box:Bay", DriveLocation{Box: 1, Bay: 4}, false},
+
+		{"", "", DriveLocation{}, true},
+		{"1I", "", DriveLocation{}, true},
+		{"1I:1:2:3", "", DriveLocation{}, true},
+		{"1I:1:7", "ControllerPort:Box", DriveLocation{}, true},
+		{"1I:x:7", "", DriveLocation{}, true},
+		{"1I:1:y", "", DriveLocation{}, true},
+		{"1I::7", "", DriveLocation{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDriveLocation(tt.location, tt.format)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseDriveLocation(%q, %q): error %v, want error %v", tt.location, tt.format, err, tt.err)
+			continue
+		}
+		if !tt.err && got != tt.want {
+			t.Errorf("ParseDriveLocation(%q, %q) = %+v, want %+v", tt.location, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeLocationValid(t *testing.T) {
+	tests := []struct {
+		location string
+		valid    bool
+	}{
+		{"1I:1:7", true},
+		{"1:4", true},
+		{"", false},
+		{"1I:x:7", false},
+		{":0:1", false},
+	}
+	for _, tt := range tests {
+		loc := decodeLocation(tt.location, "")
+		if loc.Valid() != tt.valid {
+			t.Errorf("decodeLocation(%q) = %+v, valid %v, want %v", tt.location, loc, loc.Valid(), tt.valid)
+		}
+		if !tt.valid && loc.SameBox(loc) {
+			t.Errorf("decodeLocation(%q): invalid location matches its own box", tt.location)
+		}
+	}
+}
+
+func TestBayMap(t *testing.T) {
+	ls := &LocalStorage{
+		StorageEnclosures: []LocalStorageEnclosure{
+			{Location: "1I:1", DriveBayCount: 4},
+			{Location: "bad", DriveBayCount: 2},
+		},
+		PhysicalDrives: []LocalPhysicalDrive{
+			{Location: "1I:1:1", SerialNumber: "D1"},
+			{Location: "1I:1:3", SerialNumber: "D3"},
+			{Location: "1I:1:9", SerialNumber: "D9"},
+			{Location: "2I:1:1", SerialNumber: "OTHER"},
+			{Location: "1I-1-2", SerialNumber: "BROKEN"},
+		},
+	}
+	ls.decodeLocations()
+
+	maps := ls.BayMap()
+	if len(maps) != 1 {
+		t.Fatalf("BayMap: %d maps, want 1", len(maps))
+	}
+	empty := maps[0].EmptyBays()
+	if len(empty) != 2 || empty[0] != 2 || empty[1] != 4 {
+		t.Errorf("EmptyBays = %v, want [2 4]", empty)
+	}
+	if len(maps[0].OutOfRange) != 1 || maps[0].OutOfRange[0].SerialNumber != "D9" {
+		t.Errorf("OutOfRange = %v, want D9", maps[0].OutOfRange)
+	}
+	unmapped := ls.UnmappedDrives()
+	if len(unmapped) != 2 || unmapped[0].SerialNumber != "OTHER" || unmapped[1].SerialNumber != "BROKEN" {
+		t.Errorf("UnmappedDrives = %v, want OTHER and BROKEN", unmapped)
+	}
+}
@@ -0,0 +1,206 @@
+package oneview
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//HealthNotOK  - состояние известно и не "OK", общее правило для метрик, состояния корзин и утилиты командной строки
+func HealthNotOK(health string) bool {
+	return health != "" && health != "OK"
+}
+
+//metricSample  - значение метрики с метками
+type metricSample struct {
+	labels string
+	value  float64
+}
+
+//metricFamily  - метрика в текстовом формате Prometheus
+type metricFamily struct {
+	name    string
+	help    string
+	typ     string //"gauge" или "counter"
+	samples []metricSample
+}
+
+//metricSet  - набор метрик в порядке добавления
+type metricSet struct {
+	families []*metricFamily
+	byName   map[string]*metricFamily
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{byName: make(map[string]*metricFamily)}
+}
+
+//escapeLabel  - экранирование значения метки
+func escapeLabel(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+//add  - значение метрики name, labels - пары имя, значение
+func (m *metricSet) add(name string, typ string, help string, value float64, labels ...string) {
+	f, ok := m.byName[name]
+	if !ok {
+		f = &metricFamily{name: name, help: help, typ: typ}
+		m.byName[name] = f
+		m.families = append(m.families, f)
+	}
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+`="`+escapeLabel(labels[i+1])+`"`)
+	}
+	s := metricSample{value: value}
+	if len(pairs) > 0 {
+		s.labels = "{" + strings.Join(pairs, ",") + "}"
+	}
+	f.samples = append(f.samples, s)
+}
+
+func (m *metricSet) gauge(name string, help string, value float64, labels ...string) {
+	m.add(name, "gauge", help, value, labels...)
+}
+
+func (m *metricSet) counter(name string, help string, value float64, labels ...string) {
+	m.add(name, "counter", help, value, labels...)
+}
+
+//write  - вывод в текстовом формате Prometheus 0.0.4
+func (m *metricSet) write(w io.Writer) error {
+	var b strings.Builder
+	for _, f := range m.families {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.typ)
+		for _, s := range f.samples {
+			b.WriteString(f.name + s.labels + " " + strconv.FormatFloat(s.value, 'g', -1, 64) + "\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+//boolValue  - 1 для true, 0 для false
+func boolValue(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
+
+//serverMetrics  - метрики серверов, контроллеров и корзин снимка
+func serverMetrics(m *metricSet, infra *OVInfrastructure) {
+	type stateKey struct{ endpoint, site, health, power string }
+	counts := make(map[stateKey]int)
+	for _, srv := range infra.Servers {
+		counts[stateKey{srv.Endpoint, srv.Site, srv.Base.Status, srv.Base.PowerState}]++
+	}
+	keys := make([]stateKey, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+	for _, k := range keys {
+		m.gauge("oneview_servers", "Number of servers by health and power state.", float64(counts[k]),
+			"endpoint", k.endpoint, "site", k.site, "health", k.health, "power_state", k.power)
+	}
+
+	servers := make([]*ServerHardware, len(infra.Servers))
+	copy(servers, infra.Servers)
+	sortServers(servers)
+	for _, srv := range servers {
+		labels := []string{"endpoint", srv.Endpoint, "serial", srv.Base.SerialNumber.String(), "name", srv.Base.Name}
+
+		dimms := 0
+		for _, mm := range srv.Memory.Data {
+			if HealthNotOK(mm.Status.Health) {
+				dimms++
+			}
+		}
+		m.gauge("oneview_server_memory_modules_not_ok", "Number of memory modules with health other than OK.", float64(dimms), labels...)
+
+		drives := 0
+		for _, ctrl := range srv.Storage.Data {
+			for _, d := range ctrl.PhysicalDrives {
+				if HealthNotOK(d.Status.Health) {
+					drives++
+				}
+			}
+		}
+		m.gauge("oneview_server_drives_not_ok", "Number of physical drives with health other than OK.", float64(drives), labels...)
+
+		env := srv.Environment
+		if env.PowerCapType != "" {
+			m.gauge("oneview_server_power_cap_info", "Power cap type from the environmental configuration.", 1,
+				append(labels, "power_cap_type", env.PowerCapType)...)
+		}
+		if env.CalibratedMaxPower > 0 {
+			m.gauge("oneview_server_calibrated_max_power_watts", "Calibrated maximum power of the server.", float64(env.CalibratedMaxPower), labels...)
+		}
+		if env.IdleMaxPower > 0 {
+			m.gauge("oneview_server_idle_max_power_watts", "Maximum idle power of the server.", float64(env.IdleMaxPower), labels...)
+		}
+	}
+
+	for _, srv := range servers {
+		for _, h := range GetControllerHealth(srv) {
+			labels := []string{"endpoint", srv.Endpoint, "serial", h.ServerSerialNumber, "controller", h.Controller, "model", h.Model}
+			m.gauge("oneview_controller_ok", "Controller health is OK.", boolValue(!HealthNotOK(h.Status.Health) && h.BoardOK()), labels...)
+			if h.CachePresent {
+				m.gauge("oneview_controller_cache_ok", "Controller cache module health is OK.", boolValue(!h.CacheDegraded()), labels...)
+			}
+		}
+	}
+
+	for _, enc := range infra.enclosureList() {
+		h := enc.Health()
+		labels := []string{"endpoint", enc.Endpoint, "enclosure", enc.Name, "serial", enc.SerialNumber}
+		m.gauge("oneview_enclosure_ok", "All enclosure components are OK.", boolValue(h.OK()), labels...)
+		m.gauge("oneview_enclosure_fans_not_ok", "Number of enclosure fans not OK or missing.", float64(len(h.FansNotOK)), labels...)
+		m.gauge("oneview_enclosure_power_supplies_not_ok", "Number of enclosure power supplies not OK.", float64(len(h.PowerSuppliesNotOK)), labels...)
+	}
+}
+
+//crawlerMetrics  - метрики фонового обхода
+func crawlerMetrics(m *metricSet, status CrawlerStatus) {
+	if !status.Updated.IsZero() {
+		m.gauge("oneview_crawl_timestamp_seconds", "Time the last crawl finished.", float64(status.Updated.Unix()))
+	}
+	m.gauge("oneview_crawl_duration_seconds", "Duration of the last crawl.", status.Duration.Seconds())
+	m.counter("oneview_crawls_total", "Number of completed crawls.", float64(status.Crawls))
+	for _, ep := range status.Endpoints {
+		m.gauge("oneview_endpoint_up", "Last crawl of the endpoint finished without errors.", boolValue(ep.OK()),
+			"endpoint", ep.Endpoint, "site", ep.Site)
+	}
+	for _, ep := range status.Endpoints {
+		m.gauge("oneview_endpoint_servers", "Number of servers loaded from the endpoint in the last crawl.", float64(ep.Servers),
+			"endpoint", ep.Endpoint, "site", ep.Site)
+	}
+	for _, ep := range status.Endpoints {
+		m.counter("oneview_endpoint_crawl_errors_total", "Number of errors while crawling the endpoint.", float64(status.ErrorsTotal[ep.Endpoint]),
+			"endpoint", ep.Endpoint, "site", ep.Site)
+	}
+}
+
+//MetricsHandler  - обработчик /metrics в текстовом формате Prometheus по последнему снимку обхода,
+//запрос не обращается к OneView
+func (c *Crawler) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		m := newMetricSet()
+		if infra := c.Snapshot(); infra != nil {
+			serverMetrics(m, infra)
+		}
+		crawlerMetrics(m, c.Status())
+		m.gauge("oneview_scrape_duration_seconds", "Time spent building this response.", time.Since(started).Seconds())
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.write(w)
+	})
+}
@@ -1,8 +1,8 @@
 package oneview
 
 import (
+	"encoding/json"
 	"errors"
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -14,20 +14,74 @@ type ovEndpoint struct {
 	login    string
 	password string
 	endpoint string
+	site     string //площадка (ЦОД), к которой относится точка подключения
+}
+
+//client  - создание клиента OneView для точки подключения
+func (endpoint *ovEndpoint) client() *ov.OVClient {
+	var ClientOV *ov.OVClient
+	return ClientOV.NewOVClient(
+		endpoint.login,
+		endpoint.password,
+		endpoint.domain,
+		endpoint.endpoint,
+		false,
+		3000,
+		"*")
 }
 
 //ServerHardware  - структура описывающая сервер в OneView
 type ServerHardware struct {
-	Base    ov.ServerHardware
-	Memory  ServerHardwareMemory
-	Storage ServerHardwareLocalStorage
+	Base     ov.ServerHardware
+	Memory   ServerHardwareMemory
+	Storage  ServerHardwareLocalStorage
+	Tags     []string //пользовательские метки сервера, например "regulated"
+	Endpoint string   //точка подключения OneView, с которой загружен сервер
+	Site     string   //площадка точки подключения, задается SetEndpointSite
+
+	Enclosure       *Enclosure       `json:"-"` //корзина блейд-сервера, nil для стоечных серверов
+	EnclosureHealth *EnclosureHealth //сводное состояние корзины блейд-сервера
+	Support         ServerSupport    //состояние удаленной поддержки HPE
+	Profile         *ServerProfile   //серверный профиль, загружается LoadServerProfiles
+
+	Environment EnvironmentalConfiguration //настройки питания: тип ограничения мощности, калиброванная и максимальная мощность
+}
+
+//HasTag  - проверка наличия метки у сервера
+func (s *ServerHardware) HasTag(tag string) bool {
+	for _, t := range s.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+//MarshalJSON  - сервер в JSON без клиента OneView в Base, учетные данные не попадают в ответы API, снимки и вывод
+func (s ServerHardware) MarshalJSON() ([]byte, error) {
+	type plain ServerHardware
+	p := plain(s)
+	p.Base.Client = nil
+	return json.Marshal(p)
 }
 
 //OVInfrastructure  -  структура описывающая объекты OneView
 type OVInfrastructure struct {
-	endpoints    []*ovEndpoint
-	Servers      []*ServerHardware
-	ServersCount int
+	endpoints       []*ovEndpoint
+	Servers         []*ServerHardware
+	ServersCount    int
+	Enclosures      []*Enclosure
+	EnclosuresCount int
+
+	Interconnects        []*Interconnect        //коммутационные модули, загружаются LoadInterconnects
+	LogicalInterconnects []*LogicalInterconnect //логические объединения модулей
+	UplinkSets           []*UplinkSet           //uplink set логических объединений
+
+	Profiles         []*ServerProfile         //серверные профили, загружаются LoadServerProfiles
+	ProfileTemplates []*ServerProfileTemplate //шаблоны серверных профилей
+
+	index       *ServerIndex               //индексы серверов, строятся при первом поиске
+	crawlStatus map[string]*EndpointStatus //состояние последнего обхода точек подключения
 }
 
 //AddEndpoint  - функция добавления точки подключения к списку подключений
@@ -35,6 +89,22 @@ func (infra *OVInfrastructure) AddEndpoint(endpoint string, domain string, login
 	infra.endpoints = append(infra.endpoints, &ovEndpoint{endpoint: endpoint, domain: domain, login: login, password: password})
 }
 
+//SetEndpointSite  - назначение площадки (ЦОД) точке подключения, используется при группировке серверов
+func (infra *OVInfrastructure) SetEndpointSite(endpoint string, site string) error {
+	for _, ep := range infra.endpoints {
+		if ep.endpoint == endpoint {
+			ep.site = site
+			for _, srv := range infra.Servers {
+				if srv.Endpoint == endpoint {
+					srv.Site = site
+				}
+			}
+			return nil
+		}
+	}
+	return errors.New("Endpoint " + endpoint + " not found")
+}
+
 var infrastructureGlobal *OVInfrastructure
 
 //Init  - функция инициализации глобальной структуры
@@ -45,6 +115,15 @@ func (infra *OVInfrastructure) Init() {
 	}
 	infra.Servers = make([]*ServerHardware, 0)
 	infra.ServersCount = 0
+	infra.Enclosures = make([]*Enclosure, 0)
+	infra.EnclosuresCount = 0
+	infra.Interconnects = make([]*Interconnect, 0)
+	infra.LogicalInterconnects = make([]*LogicalInterconnect, 0)
+	infra.UplinkSets = make([]*UplinkSet, 0)
+	infra.Profiles = make([]*ServerProfile, 0)
+	infra.ProfileTemplates = make([]*ServerProfileTemplate, 0)
+	infra.index = nil
+	infra.crawlStatus = make(map[string]*EndpointStatus)
 	infra.endpoints = make([]*ovEndpoint, 0)
 }
 
@@ -56,6 +135,15 @@ func (infra *OVInfrastructure) Destroy() {
 	}
 	infra.Servers = make([]*ServerHardware, 0)
 	infra.ServersCount = 0
+	infra.Enclosures = make([]*Enclosure, 0)
+	infra.EnclosuresCount = 0
+	infra.Interconnects = make([]*Interconnect, 0)
+	infra.LogicalInterconnects = make([]*LogicalInterconnect, 0)
+	infra.UplinkSets = make([]*UplinkSet, 0)
+	infra.Profiles = make([]*ServerProfile, 0)
+	infra.ProfileTemplates = make([]*ServerProfileTemplate, 0)
+	infra.index = nil
+	infra.crawlStatus = make(map[string]*EndpointStatus)
 	infra.endpoints = make([]*ovEndpoint, 0)
 }
 
@@ -84,18 +172,23 @@ func DestroyGlobalOVInfrastructureObj() {
 	}
 }
 
+//loadServerDetails  - запрос памяти и локальных хранилищ сервера, ошибки учитываются в состоянии обхода
+func loadServerDetails(ovc *ov.OVClient, s *ServerHardware, status *EndpointStatus) {
+	var err error
+	if s.Memory, err = GetServerHardwareMemory(ovc, s.Base.UUID); err != nil { //запрос по памяти в сервере
+		status.fail(err)
+	}
+	if s.Storage, err = GetServerHardwareLocalStorage(ovc, s.Base.UUID); err != nil { //запрос по локальным хранилищам
+		status.fail(err)
+	}
+}
+
 //LoadServerHardwareList  - загрузка информации со всех точек подключения по всем серверам
 func (infra *OVInfrastructure) LoadServerHardwareList() ([]*ServerHardware, error) {
 	for _, endpoint := range infra.endpoints {
-		var ClientOV *ov.OVClient
-		ovc := ClientOV.NewOVClient(
-			endpoint.login,
-			endpoint.password,
-			endpoint.domain,
-			endpoint.endpoint,
-			false,
-			3000,
-			"*")
+		first := len(infra.Servers) //серверы данной точки подключения начинаются с этого индекса
+		status := infra.beginCrawl(endpoint)
+		ovc := endpoint.client()
 		filters := []string{""}
 		sort := ""
 		start := ""
@@ -106,14 +199,16 @@ func (infra *OVInfrastructure) LoadServerHardwareList() ([]*ServerHardware, erro
 			for _, rec := range ServerList.Members {
 				s := ServerHardware{}
 				s.Base = rec
-				s.Memory, _ = GetServerHardwareMemory(ovc, rec.UUID)        //запрос по памяти в сервере
-				s.Storage, _ = GetServerHardwareLocalStorage(ovc, rec.UUID) //запрос по локальным хранилищам
+				s.Base.Client = nil //клиент библиотеки содержит учетные данные точки подключения
+				s.Endpoint = endpoint.endpoint
+				s.Site = endpoint.site
+				loadServerDetails(ovc, &s, status)
 				infra.Servers = append(infra.Servers, &s)
 				infra.ServersCount++
 
 			}
 		} else {
-			fmt.Println("Failed to fetch server List : ", err)
+			status.fail(err)
 		}
 		for i := ServerList.Count; i < ServerList.Total; i = i + ServerList.Count {
 			if ServerList.Total-i > ServerList.Count {
@@ -127,45 +222,138 @@ func (infra *OVInfrastructure) LoadServerHardwareList() ([]*ServerHardware, erro
 				for _, rec := range ServerList.Members {
 					s := ServerHardware{}
 					s.Base = rec
-					s.Memory, _ = GetServerHardwareMemory(ovc, rec.UUID)        //запрос по памяти в сервере
-					s.Storage, _ = GetServerHardwareLocalStorage(ovc, rec.UUID) //запрос по локальным хранилищам
+					s.Base.Client = nil
+					s.Endpoint = endpoint.endpoint
+					s.Site = endpoint.site
+					loadServerDetails(ovc, &s, status)
 					infra.Servers = append(infra.Servers, &s)
 					infra.ServersCount++
 				}
 			} else {
-				fmt.Println("Failed to fetch server List : ", err)
+				status.fail(err)
 			}
 		}
-		for _, srv := range infra.Servers {
-			srvHardware, err := ovc.GetServerHardwareByUri(srv.Base.URI)
-			ilo := srvHardware.GetIloIPAddress()
-			envConf, err := GetServerEnvConfig(ovc, srv.Base.UUID)
-			LoadDatcenterList(ovc, "", "", "", "")
-			mpHostName := srvHardware.MpHostInfo.MpHostName
-
-			if srvHardware.LocationURI != "" { //для корзин
-				encHardware, _ := GetServerEnclosure(ovc, srvHardware.LocationURI)
-				_ = encHardware
-				sso, _ := GetServerILOssoUrl(ovc, srvHardware.UUID)
-				java, _ := GetServerjavaRemoteConsoleUrl(ovc, srvHardware.UUID)
-				_ = sso
-				_ = java
-			} else {
-				mpHostName = strings.Split(mpHostName, "ILO-")[1]
+		enclosures := make(map[string]*Enclosure) //корзины уже запрошенные на данной точке подключения
+		for _, srv := range infra.Servers[first:] {
+			//состояние удаленной поддержки из списка серверов
+			srv.Support = serverHardwareSupport(srv.Base)
+			if srv.Environment, err = GetServerEnvConfig(ovc, srv.Base.UUID); err != nil { //настройки питания
+				status.fail(err)
+			}
+
+			if srv.Base.LocationURI != "" { //для корзин
+				enc, ok := enclosures[srv.Base.LocationURI.String()]
+				if !ok {
+					if encHardware, err := GetServerEnclosure(ovc, srv.Base.LocationURI); err == nil {
+						encHardware.Endpoint = endpoint.endpoint
+						enc = &encHardware
+					} else {
+						status.fail(err)
+					}
+					enclosures[srv.Base.LocationURI.String()] = enc
+				}
+				if enc != nil {
+					health := enc.Health()
+					srv.Enclosure = enc
+					srv.EnclosureHealth = &health
+				}
 			}
-			fmt.Println(ilo, mpHostName, envConf.PowerCapType)
-			_ = err
 		}
+		status.finish(len(infra.Servers) - first)
 	}
+	infra.linkEnclosures() //если корзины уже загружены LoadEnclosures, используются они
+	infra.linkProfiles()   //если профили уже загружены LoadServerProfiles
 	return infra.Servers, nil
 }
 
 //FindServerHardwareSN  - поиск информации со всех точек подключения по серийному номеру
 func (infra *OVInfrastructure) FindServerHardwareSN(sn string) (*ServerHardware, error) {
-	for _, srvHW := range infra.Servers {
-		if sn == string(srvHW.Base.SerialNumber) {
-			return srvHW, nil
+	if srvHW, ok := infra.Index().BySerial(sn); ok {
+		return srvHW, nil
+	}
+	return nil, errors.New("Serial Number not found")
+}
+
+//LoadServerHardwareSN  - загрузка одного сервера по серийному номеру запросом с фильтром к точкам подключения,
+//без памяти и хранилищ, с корзиной блейд-сервера; уже загруженный сервер не запрашивается повторно
+func (infra *OVInfrastructure) LoadServerHardwareSN(sn string) (*ServerHardware, error) {
+	if srvHW, ok := infra.Index().BySerial(sn); ok {
+		return srvHW, nil
+	}
+	if sn == "" || strings.ContainsAny(sn, `'"`) {
+		return nil, errors.New("Invalid serial number " + sn)
+	}
+	var lastErr error
+	for _, endpoint := range infra.endpoints {
+		ovc := endpoint.client()
+		members, err := loadCollection(ovc, "/rest/server-hardware", []string{"serialNumber='" + sn + "'"})
+		if err != nil {
+			infra.statusFor(endpoint).fail(err)
+			lastErr = err
+			continue
+		}
+		for _, rec := range members {
+			s := &ServerHardware{Endpoint: endpoint.endpoint, Site: endpoint.site}
+			if err := json.Unmarshal(rec, &s.Base); err != nil {
+				lastErr = err
+				continue
+			}
+			s.Base.Client = nil
+			if !strings.EqualFold(s.Base.SerialNumber.String(), sn) {
+				continue
+			}
+			s.Support = serverHardwareSupport(s.Base)
+			if s.Base.LocationURI != "" {
+				if enc, err := GetServerEnclosure(ovc, s.Base.LocationURI); err == nil {
+					enc.Endpoint = endpoint.endpoint
+					health := enc.Health()
+					s.Enclosure = &enc
+					s.EnclosureHealth = &health
+				} else {
+					infra.statusFor(endpoint).fail(err)
+					lastErr = err
+				}
+			}
+			infra.Servers = append(infra.Servers, s)
+			infra.ServersCount++
+			infra.index = nil
+			return s, nil
 		}
 	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
 	return nil, errors.New("Serial Number not found")
 }
+
+//FindServerHardwareUUID  - поиск информации со всех точек подключения по UUID
+func (infra *OVInfrastructure) FindServerHardwareUUID(uuid string) (*ServerHardware, error) {
+	if srvHW, ok := infra.Index().ByUUID(uuid); ok {
+		return srvHW, nil
+	}
+	return nil, errors.New("UUID not found")
+}
+
+//clientFor  - клиент OneView для точки подключения, с которой загружен объект
+func (infra *OVInfrastructure) clientFor(endpoint string) (*ov.OVClient, error) {
+	for _, ep := range infra.endpoints {
+		if ep.endpoint == endpoint {
+			return ep.client(), nil
+		}
+	}
+	return nil, errors.New("Endpoint " + endpoint + " not found")
+}
+
+//TagServerHardwareSN  - добавление меток серверу с указанным серийным номером
+func (infra *OVInfrastructure) TagServerHardwareSN(sn string, tags ...string) error {
+	srvHW, err := infra.FindServerHardwareSN(sn)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if !srvHW.HasTag(tag) {
+			srvHW.Tags = append(srvHW.Tags, tag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package oneview
+
+import (
+	"errors"
+	"time"
+
+	"github.com/HewlettPackard/oneview-golang/ov"
+	"github.com/HewlettPackard/oneview-golang/rest"
+	"github.com/HewlettPackard/oneview-golang/utils"
+)
+
+//PowerAction  - операция управления питанием сервера
+type PowerAction string
+
+const (
+	PowerOn           PowerAction = "PowerOn"      //включение
+	PowerShutdown     PowerAction = "Shutdown"     //корректное выключение (кратковременное нажатие кнопки)
+	PowerPressAndHold PowerAction = "PressAndHold" //принудительное выключение (удержание кнопки)
+	PowerColdBoot     PowerAction = "ColdBoot"     //холодная перезагрузка
+	PowerReset        PowerAction = "Reset"        //сброс
+)
+
+//powerStateRequest  - тело запроса изменения состояния питания
+type powerStateRequest struct {
+	PowerState   string `json:"powerState"`
+	PowerControl string `json:"powerControl"`
+}
+
+var powerActionRequests = map[PowerAction]powerStateRequest{
+	PowerOn:           {PowerState: "On", PowerControl: "MomentaryPress"},
+	PowerShutdown:     {PowerState: "Off", PowerControl: "MomentaryPress"},
+	PowerPressAndHold: {PowerState: "Off", PowerControl: "PressAndHold"},
+	PowerColdBoot:     {PowerState: "On", PowerControl: "ColdBoot"},
+	PowerReset:        {PowerState: "On", PowerControl: "Reset"},
+}
+
+//SetServerPowerState  - выполнение операции управления питанием сервера по uuid с ожиданием завершения задачи
+func SetServerPowerState(c *ov.OVClient, uuid utils.Nstring, action PowerAction, timeout time.Duration) (TaskResult, error) {
+	req, ok := powerActionRequests[action]
+	if !ok {
+		return TaskResult{}, errors.New("Unknown power action " + string(action))
+	}
+	return runTaskTimeout(c, rest.PUT, "/rest/server-hardware/"+uuid.String()+"/powerState", req, timeout)
+}
+
+//powerServer  - управление питанием загруженного сервера
+func (infra *OVInfrastructure) powerServer(srv *ServerHardware, action PowerAction, timeout time.Duration) (TaskResult, error) {
+	c, err := infra.clientFor(srv.Endpoint)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	result, err := SetServerPowerState(c, srv.Base.UUID, action, timeout)
+	if err == nil {
+		srv.Base.PowerState = powerActionRequests[action].PowerState
+	}
+	return result, err
+}
+
+//PowerServerSN  - управление питанием сервера с указанным серийным номером
+func (infra *OVInfrastructure) PowerServerSN(sn string, action PowerAction, timeout time.Duration) (TaskResult, error) {
+	srv, err := infra.FindServerHardwareSN(sn)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	return infra.powerServer(srv, action, timeout)
+}
+
+//PowerServerUUID  - управление питанием сервера с указанным UUID
+func (infra *OVInfrastructure) PowerServerUUID(uuid string, action PowerAction, timeout time.Duration) (TaskResult, error) {
+	srv, err := infra.FindServerHardwareUUID(uuid)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	return infra.powerServer(srv, action, timeout)
+}
@@ -0,0 +1,150 @@
+package oneview
+
+//ProfileConnectionBoot  - настройка загрузки через подключение
+type ProfileConnectionBoot struct {
+	Priority string `json:"priority"` //"Primary", "Secondary", "NotBootable"
+}
+
+//ProfileConnection  - сетевое или FC подключение серверного профиля
+type ProfileConnection struct {
+	ID            int                   `json:"id"`
+	Name          string                `json:"name"`
+	FunctionType  string                `json:"functionType"` //"Ethernet", "FibreChannel", "iSCSI"
+	NetworkURI    string                `json:"networkUri"`
+	PortID        string                `json:"portId"` //"Mezz 3:1-a"
+	RequestedMbps string                `json:"requestedMbps"`
+	MAC           string                `json:"mac"`
+	WWPN          string                `json:"wwpn"`
+	WWNN          string                `json:"wwnn"`
+	Boot          ProfileConnectionBoot `json:"boot"`
+}
+
+//ProfileConnectionSettings  - подключения серверного профиля
+type ProfileConnectionSettings struct {
+	ManageConnections bool                `json:"manageConnections"`
+	Connections       []ProfileConnection `json:"connections"`
+}
+
+//ProfileBoot  - порядок загрузки
+type ProfileBoot struct {
+	ManageBoot bool     `json:"manageBoot"`
+	Order      []string `json:"order"` //"HardDisk", "PXE", "CD", ...
+}
+
+//ProfileBootMode  - режим загрузки
+type ProfileBootMode struct {
+	ManageMode bool   `json:"manageMode"`
+	Mode       string `json:"mode"` //"UEFI", "UEFIOptimized", "BIOS"
+}
+
+//ProfileBiosSetting  - переопределенная настройка BIOS
+type ProfileBiosSetting struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+//ProfileBios  - настройки BIOS профиля
+type ProfileBios struct {
+	ManageBios         bool                 `json:"manageBios"`
+	OverriddenSettings []ProfileBiosSetting `json:"overriddenSettings"`
+}
+
+//ServerProfile  - серверный профиль OneView
+type ServerProfile struct {
+	Type                     string                    `json:"type"`
+	URI                      string                    `json:"uri"`
+	Name                     string                    `json:"name"`
+	Description              string                    `json:"description"`
+	Status                   string                    `json:"status"`
+	State                    string                    `json:"state"`
+	ServerHardwareURI        string                    `json:"serverHardwareUri"`
+	ServerHardwareTypeURI    string                    `json:"serverHardwareTypeUri"`
+	ServerProfileTemplateURI string                    `json:"serverProfileTemplateUri"`
+	TemplateCompliance       string                    `json:"templateCompliance"` //"Compliant", "NonCompliant", "Unknown"
+	ConnectionSettings       ProfileConnectionSettings `json:"connectionSettings"`
+	Boot                     ProfileBoot               `json:"boot"`
+	BootMode                 ProfileBootMode           `json:"bootMode"`
+	Bios                     ProfileBios               `json:"bios"`
+	Endpoint                 string                    `json:"endpoint,omitempty"` //точка подключения OneView, с которой загружен профиль
+
+	Template *ServerProfileTemplate `json:"-"` //шаблон профиля, nil если не назначен или не загружен
+}
+
+//Drifted  - профиль не соответствует назначенному шаблону
+func (p *ServerProfile) Drifted() bool {
+	return p.ServerProfileTemplateURI != "" && p.TemplateCompliance == "NonCompliant"
+}
+
+//ServerProfileTemplate  - шаблон серверного профиля OneView
+type ServerProfileTemplate struct {
+	Type                  string                    `json:"type"`
+	URI                   string                    `json:"uri"`
+	Name                  string                    `json:"name"`
+	Description           string                    `json:"description"`
+	Status                string                    `json:"status"`
+	State                 string                    `json:"state"`
+	ServerHardwareTypeURI string                    `json:"serverHardwareTypeUri"`
+	EnclosureGroupURI     string                    `json:"enclosureGroupUri"`
+	ConnectionSettings    ProfileConnectionSettings `json:"connectionSettings"`
+	Boot                  ProfileBoot               `json:"boot"`
+	BootMode              ProfileBootMode           `json:"bootMode"`
+	Bios                  ProfileBios               `json:"bios"`
+	Endpoint              string                    `json:"endpoint,omitempty"`
+}
+
+//LoadServerProfiles  - загрузка серверных профилей и шаблонов со всех точек подключения и привязка их к серверам,
+//при повторной загрузке профили и шаблоны с той же точки подключения и URI заменяются новыми данными
+func (infra *OVInfrastructure) LoadServerProfiles() ([]*ServerProfile, error) {
+	var err, lastErr error
+
+	infra.ProfileTemplates, err = loadMembers(infra, "/rest/server-profile-templates", infra.ProfileTemplates,
+		func(t *ServerProfileTemplate) string { return t.Endpoint + t.URI },
+		func(t *ServerProfileTemplate, endpoint string) { t.Endpoint = endpoint })
+	if err != nil {
+		lastErr = err
+	}
+	infra.Profiles, err = loadMembers(infra, "/rest/server-profiles", infra.Profiles,
+		func(p *ServerProfile) string { return p.Endpoint + p.URI },
+		func(p *ServerProfile, endpoint string) { p.Endpoint = endpoint })
+	if err != nil {
+		lastErr = err
+	}
+	infra.linkProfiles()
+	return infra.Profiles, lastErr
+}
+
+//linkProfiles  - привязка профилей к шаблонам и серверам, сервер без профиля отвязывается,
+//сервер, профиль которого не загружен, сохраняет прежнюю привязку
+func (infra *OVInfrastructure) linkProfiles() {
+	templates := make(map[string]*ServerProfileTemplate)
+	for _, t := range infra.ProfileTemplates {
+		templates[t.Endpoint+t.URI] = t
+	}
+	profiles := make(map[string]*ServerProfile)
+	for _, p := range infra.Profiles {
+		p.Template = templates[p.Endpoint+p.ServerProfileTemplateURI]
+		profiles[p.Endpoint+p.URI] = p
+	}
+	for _, srv := range infra.Servers {
+		uri := srv.Base.ServerProfileURI.String()
+		if uri == "" {
+			srv.Profile = nil
+			continue
+		}
+		if p, ok := profiles[srv.Endpoint+uri]; ok {
+			srv.Profile = p
+		}
+	}
+	infra.index = nil
+}
+
+//DriftedServers  - серверы, профили которых не соответствуют назначенному шаблону
+func (infra *OVInfrastructure) DriftedServers() []*ServerHardware {
+	list := make([]*ServerHardware, 0)
+	for _, srv := range infra.Servers {
+		if srv.Profile != nil && srv.Profile.Drifted() {
+			list = append(list, srv)
+		}
+	}
+	return list
+}
@@ -0,0 +1,302 @@
+package oneview
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//ServerIndex  - индексы загруженных серверов для быстрого поиска
+type ServerIndex struct {
+	count      int
+	bySerial   map[string]*ServerHardware
+	byUUID     map[string]*ServerHardware
+	byName     map[string]*ServerHardware
+	byIloIP    map[string]*ServerHardware
+	byMAC      map[string]*ServerHardware
+	byBay      map[string]*ServerHardware
+	byEndpoint map[string][]*ServerHardware
+}
+
+//normalizeMAC  - MAC адрес без разделителей в верхнем регистре "AABBCCDDEEFF",
+//одинаков для записей "aa:bb:cc:dd:ee:ff", "AA-BB-CC-DD-EE-FF" и "aabb.ccdd.eeff"
+func normalizeMAC(mac string) string {
+	mac = strings.ToUpper(strings.TrimSpace(mac))
+	return strings.NewReplacer(":", "", "-", "", ".", "", " ", "").Replace(mac)
+}
+
+//collectMACs  - значения всех ключей "mac" в дереве JSON
+func collectMACs(node interface{}, macs []string) []string {
+	switch x := node.(type) {
+	case []interface{}:
+		for _, item := range x {
+			macs = collectMACs(item, macs)
+		}
+	case map[string]interface{}:
+		for k, v := range x {
+			if mac, ok := v.(string); ok && strings.EqualFold(k, "mac") && mac != "" {
+				macs = append(macs, mac)
+				continue
+			}
+			macs = collectMACs(v, macs)
+		}
+	}
+	return macs
+}
+
+//serverMACs  - MAC адреса физических и виртуальных портов сервера (Base.PortMap) и подключений его профиля
+func serverMACs(srv *ServerHardware) []string {
+	macs := make([]string, 0)
+	if data, err := json.Marshal(srv.Base.PortMap); err == nil {
+		var doc interface{}
+		if json.Unmarshal(data, &doc) == nil {
+			macs = collectMACs(doc, macs)
+		}
+	}
+	if srv.Profile != nil {
+		for _, conn := range srv.Profile.ConnectionSettings.Connections {
+			if conn.MAC != "" {
+				macs = append(macs, conn.MAC)
+			}
+		}
+	}
+	return macs
+}
+
+//addFirst  - добавление в индекс, при повторе ключа сохраняется первый сервер (порядок загрузки)
+func addFirst(m map[string]*ServerHardware, key string, srv *ServerHardware) {
+	if _, ok := m[key]; !ok {
+		m[key] = srv
+	}
+}
+
+//bayKey  - ключ индекса по корзине и номеру отсека
+func bayKey(enclosure string, bay int) string {
+	return strings.ToUpper(enclosure) + "/" + strconv.Itoa(bay)
+}
+
+//serverBay  - номер отсека блейд-сервера в его корзине, 0 если не найден
+func serverBay(srv *ServerHardware) int {
+	if srv.Enclosure == nil {
+		return 0
+	}
+	for _, bay := range srv.Enclosure.DeviceBays {
+		if bay.DeviceURI != "" && bay.DeviceURI == srv.Base.URI.String() {
+			return bay.BayNumber
+		}
+	}
+	return srv.Base.Position
+}
+
+//NewServerIndex  - построение индексов по серийному номеру, UUID, имени, адресу iLO, MAC, отсеку корзины и точке подключения,
+//при повторе значения (сервер доступен с нескольких точек подключения) находится первый загруженный сервер
+func NewServerIndex(servers []*ServerHardware) *ServerIndex {
+	ix := &ServerIndex{
+		count:      len(servers),
+		bySerial:   make(map[string]*ServerHardware),
+		byUUID:     make(map[string]*ServerHardware),
+		byName:     make(map[string]*ServerHardware),
+		byIloIP:    make(map[string]*ServerHardware),
+		byMAC:      make(map[string]*ServerHardware),
+		byBay:      make(map[string]*ServerHardware),
+		byEndpoint: make(map[string][]*ServerHardware),
+	}
+	for _, srv := range servers {
+		addFirst(ix.bySerial, srv.Base.SerialNumber.String(), srv)
+		addFirst(ix.byUUID, strings.ToUpper(srv.Base.UUID.String()), srv)
+		addFirst(ix.byName, srv.Base.Name, srv)
+		if ip := srv.Base.GetIloIPAddress(); ip != "" {
+			addFirst(ix.byIloIP, ip, srv)
+		}
+		for _, mac := range serverMACs(srv) {
+			addFirst(ix.byMAC, normalizeMAC(mac), srv)
+		}
+		if bay := serverBay(srv); bay > 0 {
+			for _, id := range []string{srv.Enclosure.UUID, srv.Enclosure.Name, srv.Enclosure.SerialNumber} {
+				if id != "" {
+					addFirst(ix.byBay, bayKey(id, bay), srv)
+				}
+			}
+		}
+		ix.byEndpoint[srv.Endpoint] = append(ix.byEndpoint[srv.Endpoint], srv)
+	}
+	return ix
+}
+
+//BySerial  - поиск по серийному номеру
+func (ix *ServerIndex) BySerial(sn string) (*ServerHardware, bool) {
+	srv, ok := ix.bySerial[sn]
+	return srv, ok
+}
+
+//ByUUID  - поиск по UUID без учета регистра
+func (ix *ServerIndex) ByUUID(uuid string) (*ServerHardware, bool) {
+	srv, ok := ix.byUUID[strings.ToUpper(uuid)]
+	return srv, ok
+}
+
+//ByName  - поиск по имени в OneView "enc1, bay 3"
+func (ix *ServerIndex) ByName(name string) (*ServerHardware, bool) {
+	srv, ok := ix.byName[name]
+	return srv, ok
+}
+
+//ByIloIP  - поиск по адресу iLO
+func (ix *ServerIndex) ByIloIP(ip string) (*ServerHardware, bool) {
+	srv, ok := ix.byIloIP[ip]
+	return srv, ok
+}
+
+//ByMAC  - поиск по MAC адресу порта сервера или подключения серверного профиля без учета разделителей и регистра
+func (ix *ServerIndex) ByMAC(mac string) (*ServerHardware, bool) {
+	srv, ok := ix.byMAC[normalizeMAC(mac)]
+	return srv, ok
+}
+
+//ByBay  - поиск по корзине (UUID, имя или серийный номер) и номеру отсека
+func (ix *ServerIndex) ByBay(enclosure string, bay int) (*ServerHardware, bool) {
+	srv, ok := ix.byBay[bayKey(enclosure, bay)]
+	return srv, ok
+}
+
+//ByEndpoint  - серверы точки подключения в порядке загрузки
+func (ix *ServerIndex) ByEndpoint(endpoint string) []*ServerHardware {
+	return ix.byEndpoint[endpoint]
+}
+
+//Index  - индексы загруженных серверов, перестраиваются после загрузки
+func (infra *OVInfrastructure) Index() *ServerIndex {
+	if infra.index == nil || infra.index.count != len(infra.Servers) {
+		infra.index = NewServerIndex(infra.Servers)
+	}
+	return infra.index
+}
+
+//ServerPredicate  - условие отбора серверов
+type ServerPredicate func(srv *ServerHardware) bool
+
+//And  - все условия выполняются
+func And(preds ...ServerPredicate) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		for _, p := range preds {
+			if !p(srv) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+//Or  - выполняется хотя бы одно условие
+func Or(preds ...ServerPredicate) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		for _, p := range preds {
+			if p(srv) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+//Not  - условие не выполняется
+func Not(pred ServerPredicate) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		return !pred(srv)
+	}
+}
+
+//containsFold  - подстрока без учета регистра
+func containsFold(s string, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+//ModelContains  - модель сервера содержит строку "BL460c"
+func ModelContains(model string) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		return containsFold(srv.Base.Model, model)
+	}
+}
+
+//ProcessorTypeContains  - тип процессора содержит строку "E5-2695"
+func ProcessorTypeContains(processor string) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		return containsFold(srv.Base.ProcessorType, processor)
+	}
+}
+
+//MemoryMbBetween  - объем памяти в диапазоне [min, max], max = 0 - без ограничения сверху
+func MemoryMbBetween(min int, max int) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		return srv.Base.MemoryMb >= min && (max == 0 || srv.Base.MemoryMb <= max)
+	}
+}
+
+//PowerStateIs  - состояние питания "On", "Off"
+func PowerStateIs(state string) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		return strings.EqualFold(srv.Base.PowerState, state)
+	}
+}
+
+//HealthIs  - состояние сервера в OneView "OK", "Warning", "Critical"
+func HealthIs(status string) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		return strings.EqualFold(srv.Base.Status, status)
+	}
+}
+
+//EndpointIs  - сервер загружен с точки подключения
+func EndpointIs(endpoint string) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		return srv.Endpoint == endpoint
+	}
+}
+
+//firmwarePredicate  - хотя бы один компонент типа t (и модели, если задана) удовлетворяет сравнению версии
+func firmwarePredicate(t FirmwareComponentType, model string, match func(cmp int) bool, version string) ServerPredicate {
+	return func(srv *ServerHardware) bool {
+		for _, rec := range ServerFirmwareInventory(srv) {
+			if rec.ComponentType != t || (model != "" && !strings.EqualFold(rec.Model, model)) {
+				continue
+			}
+			if match(CompareFirmwareVersions(rec.Version, version)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+//FirmwareVersionAtLeast  - у сервера есть компонент типа t (модели model, если задана) с версией не ниже version
+func FirmwareVersionAtLeast(t FirmwareComponentType, model string, version string) ServerPredicate {
+	return firmwarePredicate(t, model, func(cmp int) bool { return cmp >= 0 }, version)
+}
+
+//FirmwareVersionBelow  - у сервера есть компонент типа t (модели model, если задана) с версией ниже version
+func FirmwareVersionBelow(t FirmwareComponentType, model string, version string) ServerPredicate {
+	return firmwarePredicate(t, model, func(cmp int) bool { return cmp < 0 }, version)
+}
+
+//sortServers  - упорядочивание по точке подключения и серийному номеру
+func sortServers(list []*ServerHardware) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Endpoint != list[j].Endpoint {
+			return list[i].Endpoint < list[j].Endpoint
+		}
+		return list[i].Base.SerialNumber < list[j].Base.SerialNumber
+	})
+}
+
+//FilterServers  - серверы, удовлетворяющие условию, упорядоченные по точке подключения и серийному номеру
+func (infra *OVInfrastructure) FilterServers(pred ServerPredicate) []*ServerHardware {
+	list := make([]*ServerHardware, 0)
+	for _, srv := range infra.Servers {
+		if pred == nil || pred(srv) {
+			list = append(list, srv)
+		}
+	}
+	sortServers(list)
+	return list
+}
@@ -0,0 +1,650 @@
+package oneview
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//Язык фильтрации серверов:
+//
+//	model ~ "BL460c" and memoryMb >= 262144 and storage.drives.health != "OK"
+//	all(memory.status.health == "OK") or not (powerState == "On")
+//
+//Поля задаются путем по дереву ServerHardware без учета регистра: поля Base доступны без префикса,
+//memory и storage - списки модулей памяти и контроллеров, drives и logicalDrives - диски контроллера,
+//health - Status.Health (или status, если это строка). Сравнение по списку истинно, если выполняется
+//хотя бы для одного элемента. any(...) и all(...) вычисляют выражение отдельно для каждого элемента
+//общего списка его полей (для storage.drives.* - для каждого диска) и истинны, если оно выполняется
+//хотя бы для одного или для всех элементов, all(...) по пустому списку истинно, any(...) - ложно.
+//Операции: == != < <= > >= и ~ !~ (регулярное выражение без учета регистра),
+//строки сравниваются как версии (CompareFirmwareVersions), == и != для строк без учета регистра.
+
+//ParseError  - ошибка разбора выражения с позицией в исходной строке
+type ParseError struct {
+	Pos int    //позиция (с 1) в выражении
+	Msg string //описание ошибки
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("query parse error at position %d: %s", e.Pos, e.Msg)
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string //текст лексемы, для строк - значение без кавычек
+	pos  int
+}
+
+//lexQuery  - разбиение выражения на лексемы, выражение разбирается по символам UTF-8,
+//позиции лексем и ошибок считаются в символах
+func lexQuery(src string) ([]token, error) {
+	tokens := make([]token, 0)
+	pos := func(i int) int { return utf8.RuneCountInString(src[:i]) + 1 }
+	isIdent := func(r rune) bool { return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	i := 0
+	for i < len(src) {
+		c, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokLParen, "(", pos(i)})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokRParen, ")", pos(i)})
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(src) && rune(src[j]) != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				return nil, &ParseError{Pos: pos(i), Msg: "unterminated string"}
+			}
+			raw := src[i+1 : j]
+			text := strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\'`, `'`).Replace(raw)
+			tokens = append(tokens, token{tokString, text, pos(i)})
+			i = j + 1
+		case strings.ContainsRune("=!<>~&|", c):
+			op := string(c)
+			if i+1 < len(src) {
+				two := src[i : i+2]
+				switch two {
+				case "==", "!=", "<=", ">=", "!~", "&&", "||":
+					op = two
+				}
+			}
+			switch op {
+			case "&", "|":
+				return nil, &ParseError{Pos: pos(i), Msg: "unexpected '" + op + "'"}
+			}
+			tokens = append(tokens, token{tokOp, op, pos(i)})
+			i += len(op)
+		case c >= '0' && c <= '9' || (c == '-' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9'):
+			j := i + 1
+			for j < len(src) && (src[j] >= '0' && src[j] <= '9' || src[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{tokNumber, src[i:j], pos(i)})
+			i = j
+		case c == '_' || unicode.IsLetter(c):
+			j := i + size
+			for j < len(src) {
+				r, n := utf8.DecodeRuneInString(src[j:])
+				if !isIdent(r) {
+					break
+				}
+				j += n
+			}
+			tokens = append(tokens, token{tokIdent, src[i:j], pos(i)})
+			i = j
+		default:
+			return nil, &ParseError{Pos: pos(i), Msg: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	tokens = append(tokens, token{tokEOF, "", pos(len(src))})
+	return tokens, nil
+}
+
+//queryNode  - узел дерева выражения, root - doc является корнем дерева сервера
+type queryNode interface {
+	eval(doc interface{}, root bool) bool
+}
+
+type andNode struct{ left, right queryNode }
+type orNode struct{ left, right queryNode }
+type notNode struct{ expr queryNode }
+
+//quantNode  - any(...) или all(...): выражение вычисляется для каждого элемента списка path,
+//пути сравнений внутри выражения заданы относительно элемента
+type quantNode struct {
+	all  bool
+	path []string
+	expr queryNode
+}
+type compareNode struct {
+	path  []string
+	op    string
+	value interface{} //string, float64 или bool
+	re    *regexp.Regexp
+}
+
+func (n andNode) eval(doc interface{}, root bool) bool {
+	return n.left.eval(doc, root) && n.right.eval(doc, root)
+}
+
+func (n orNode) eval(doc interface{}, root bool) bool {
+	return n.left.eval(doc, root) || n.right.eval(doc, root)
+}
+
+func (n notNode) eval(doc interface{}, root bool) bool {
+	return !n.expr.eval(doc, root)
+}
+
+func (n quantNode) eval(doc interface{}, root bool) bool {
+	elements := []interface{}{doc}
+	if len(n.path) > 0 {
+		elements = resolvePath(doc, n.path, root)
+		root = false
+	}
+	for _, el := range elements {
+		ok := n.expr.eval(el, root)
+		if n.all && !ok {
+			return false
+		}
+		if !n.all && ok {
+			return true
+		}
+	}
+	return n.all
+}
+
+//compareNode  - сравнение истинно, если выполняется хотя бы для одного значения поля
+func (n compareNode) eval(doc interface{}, root bool) bool {
+	for _, v := range resolvePath(doc, n.path, root) {
+		if n.match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+//match  - сравнение одного значения поля с литералом
+func (n compareNode) match(v interface{}) bool {
+	if n.re != nil {
+		ok := n.re.MatchString(scalarString(v))
+		return ok == (n.op == "~")
+	}
+	var cmp int
+	switch lit := n.value.(type) {
+	case float64:
+		f, ok := scalarNumber(v)
+		if !ok {
+			return n.op == "!="
+		}
+		switch {
+		case f < lit:
+			cmp = -1
+		case f > lit:
+			cmp = 1
+		}
+	case bool:
+		b, ok := v.(bool)
+		if !ok || b != lit {
+			cmp = 1
+		}
+	case string:
+		s := scalarString(v)
+		if n.op == "==" || n.op == "!=" {
+			if !strings.EqualFold(s, lit) {
+				cmp = 1
+			}
+		} else {
+			cmp = CompareFirmwareVersions(s, lit)
+		}
+	}
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+//scalarString  - строковое представление значения поля
+func scalarString(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+//scalarNumber  - числовое значение поля, строки разбираются как числа
+func scalarNumber(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+//queryAliases  - короткие имена полей дерева ServerHardware
+var queryAliases = map[string]string{
+	"drives":        "PhysicalDrives",
+	"logicaldrives": "LogicalDrives",
+	"datadrives":    "DataDrives",
+	"enclosures":    "StorageEnclosures",
+}
+
+//lookupKey  - значение ключа map без учета регистра
+func lookupKey(m map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+//resolveSegment  - значения сегмента пути в узле, списки раскрываются
+func resolveSegment(node interface{}, seg string, root bool) []interface{} {
+	switch x := node.(type) {
+	case []interface{}:
+		out := make([]interface{}, 0)
+		for _, item := range x {
+			out = append(out, resolveSegment(item, seg, false)...)
+		}
+		return out
+	case map[string]interface{}:
+		if v, ok := lookupKey(x, seg); ok {
+			if m, isMap := v.(map[string]interface{}); isMap && (strings.EqualFold(seg, "memory") || strings.EqualFold(seg, "storage")) {
+				if data, ok := lookupKey(m, "data"); ok {
+					v = data //memory и storage - списки модулей и контроллеров
+				}
+			}
+			return flatten(v)
+		}
+		if alias, ok := queryAliases[strings.ToLower(seg)]; ok {
+			if v, ok := lookupKey(x, alias); ok {
+				return flatten(v)
+			}
+		}
+		if strings.EqualFold(seg, "health") {
+			if st, ok := lookupKey(x, "status"); ok {
+				if m, isMap := st.(map[string]interface{}); isMap {
+					return resolveSegment(m, "health", false)
+				}
+				return flatten(st)
+			}
+		}
+		if root {
+			if base, ok := lookupKey(x, "Base"); ok {
+				return resolveSegment(base, seg, false)
+			}
+		}
+	}
+	return nil
+}
+
+//flatten  - значение как список (списки раскрываются, null - пустой список)
+func flatten(v interface{}) []interface{} {
+	if v == nil {
+		return nil
+	}
+	if list, ok := v.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{v}
+}
+
+//resolvePath  - значения поля по пути от узла, root - узел является корнем документа
+func resolvePath(node interface{}, path []string, root bool) []interface{} {
+	nodes := []interface{}{node}
+	for i, seg := range path {
+		next := make([]interface{}, 0)
+		for _, n := range nodes {
+			next = append(next, resolveSegment(n, seg, root && i == 0)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+//resolveQueryPath  - значения поля по пути от корня документа
+func resolveQueryPath(doc interface{}, path []string) []interface{} {
+	return resolvePath(doc, path, true)
+}
+
+//serverDocument  - дерево сервера в виде map для вычисления выражений
+func serverDocument(srv *ServerHardware) interface{} {
+	var doc interface{}
+	data, err := json.Marshal(srv)
+	if err != nil {
+		return nil
+	}
+	json.Unmarshal(data, &doc)
+	return doc
+}
+
+//ServerFieldValues  - строковые значения поля сервера по пути языка запросов, например "storage.drives.model"
+func ServerFieldValues(srv *ServerHardware, path string) []string {
+	values := resolveQueryPath(serverDocument(srv), strings.Split(path, "."))
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		list = append(list, scalarString(v))
+	}
+	return list
+}
+
+//queryParser  - рекурсивный разбор выражения
+type queryParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+//keyword  - текущая лексема является ключевым словом
+func (p *queryParser) keyword(words ...string) bool {
+	t := p.peek()
+	if t.kind == tokIdent {
+		for _, w := range words {
+			if strings.EqualFold(t.text, w) {
+				return true
+			}
+		}
+	}
+	if t.kind == tokOp {
+		for _, w := range words {
+			if (w == "and" && t.text == "&&") || (w == "or" && t.text == "||") || (w == "not" && t.text == "!") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.keyword("not") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parseGroup() (queryNode, error) {
+	open := p.next()
+	if open.kind != tokLParen {
+		return nil, &ParseError{Pos: open.pos, Msg: "expected '('"}
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokRParen {
+		return nil, &ParseError{Pos: t.pos, Msg: "expected ')' to close '(' at position " + strconv.Itoa(open.pos)}
+	}
+	return expr, nil
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokLParen:
+		return p.parseGroup()
+	case p.keyword("any", "all") && p.tokens[p.pos+1].kind == tokLParen:
+		p.next()
+		expr, err := p.parseGroup()
+		if err != nil {
+			return nil, err
+		}
+		return bindQuantifier(strings.EqualFold(t.text, "all"), expr), nil
+	case t.kind == tokIdent:
+		return p.parseComparison()
+	case t.kind == tokEOF:
+		return nil, &ParseError{Pos: t.pos, Msg: "unexpected end of query, expected field name"}
+	}
+	return nil, &ParseError{Pos: t.pos, Msg: "expected field name, got '" + t.text + "'"}
+}
+
+func (p *queryParser) parseComparison() (queryNode, error) {
+	field := p.next()
+	path := strings.Split(field.text, ".")
+	for _, seg := range path {
+		if seg == "" {
+			return nil, &ParseError{Pos: field.pos, Msg: "invalid field name '" + field.text + "'"}
+		}
+	}
+	op := p.next()
+	if op.kind != tokOp || op.text == "&&" || op.text == "||" || op.text == "!" {
+		return nil, &ParseError{Pos: op.pos, Msg: "expected comparison operator after '" + field.text + "'"}
+	}
+	if op.text == "=" {
+		op.text = "=="
+	}
+	lit := p.next()
+	node := compareNode{path: path, op: op.text}
+	switch lit.kind {
+	case tokString:
+		node.value = lit.text
+	case tokNumber:
+		f, err := strconv.ParseFloat(lit.text, 64)
+		if err != nil {
+			return nil, &ParseError{Pos: lit.pos, Msg: "invalid number '" + lit.text + "'"}
+		}
+		node.value = f
+	case tokIdent:
+		switch strings.ToLower(lit.text) {
+		case "true":
+			node.value = true
+		case "false":
+			node.value = false
+		default:
+			return nil, &ParseError{Pos: lit.pos, Msg: "expected value, got '" + lit.text + "' (quote strings)"}
+		}
+	default:
+		return nil, &ParseError{Pos: lit.pos, Msg: "expected value after '" + op.text + "'"}
+	}
+	switch node.op {
+	case "~", "!~":
+		re, err := regexp.Compile("(?i)" + scalarString(node.value))
+		if err != nil {
+			return nil, &ParseError{Pos: lit.pos, Msg: "invalid regular expression: " + err.Error()}
+		}
+		node.re = re
+	case "<", "<=", ">", ">=":
+		if _, ok := node.value.(bool); ok {
+			return nil, &ParseError{Pos: op.pos, Msg: "operator " + node.op + " is not defined for booleans"}
+		}
+	}
+	return node, nil
+}
+
+//quantPaths  - пути списков, по которым может быть привязан квантор: пути сравнений без последнего
+//сегмента и пути вложенных кванторов
+func quantPaths(n queryNode) [][]string {
+	switch x := n.(type) {
+	case andNode:
+		return append(quantPaths(x.left), quantPaths(x.right)...)
+	case orNode:
+		return append(quantPaths(x.left), quantPaths(x.right)...)
+	case notNode:
+		return quantPaths(x.expr)
+	case quantNode:
+		return [][]string{x.path}
+	case compareNode:
+		return [][]string{x.path[:len(x.path)-1]}
+	}
+	return nil
+}
+
+//stripPath  - пути сравнений и вложенных кванторов относительно элемента списка prefix
+func stripPath(n queryNode, prefix int) queryNode {
+	switch x := n.(type) {
+	case andNode:
+		return andNode{stripPath(x.left, prefix), stripPath(x.right, prefix)}
+	case orNode:
+		return orNode{stripPath(x.left, prefix), stripPath(x.right, prefix)}
+	case notNode:
+		return notNode{stripPath(x.expr, prefix)}
+	case quantNode:
+		x.path = x.path[prefix:]
+		return x
+	case compareNode:
+		x.path = x.path[prefix:]
+		return x
+	}
+	return n
+}
+
+//bindQuantifier  - квантор по общему списку полей выражения, например для
+//any(storage.drives.health != "OK" and storage.drives.mediaType == "HDD") - по дискам storage.drives,
+//так что оба условия проверяются для одного и того же диска
+func bindQuantifier(all bool, expr queryNode) queryNode {
+	paths := quantPaths(expr)
+	prefix := paths[0]
+	for _, path := range paths[1:] {
+		n := 0
+		for n < len(prefix) && n < len(path) && strings.EqualFold(prefix[n], path[n]) {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return quantNode{all: all, path: prefix, expr: stripPath(expr, len(prefix))}
+}
+
+//Query  - разобранное выражение фильтрации серверов
+type Query struct {
+	src  string
+	root queryNode
+}
+
+//ParseQuery  - разбор выражения фильтрации, ошибки возвращаются как *ParseError
+func ParseQuery(src string) (*Query, error) {
+	tokens, err := lexQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &ParseError{Pos: t.pos, Msg: "unexpected '" + t.text + "', expected 'and', 'or' or end of query"}
+	}
+	return &Query{src: src, root: root}, nil
+}
+
+//String  - исходный текст выражения
+func (q *Query) String() string {
+	return q.src
+}
+
+//Match  - сервер удовлетворяет выражению
+func (q *Query) Match(srv *ServerHardware) bool {
+	return q.root.eval(serverDocument(srv), true)
+}
+
+//Predicate  - выражение как условие отбора для FilterServers и And/Or
+func (q *Query) Predicate() ServerPredicate {
+	return q.Match
+}
+
+//QueryServers  - серверы, удовлетворяющие выражению, упорядоченные по точке подключения и серийному номеру
+func (infra *OVInfrastructure) QueryServers(src string) ([]*ServerHardware, error) {
+	q, err := ParseQuery(src)
+	if err != nil {
+		return nil, err
+	}
+	return infra.FilterServers(q.Predicate()), nil
+}
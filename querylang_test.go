@@ -0,0 +1,160 @@
+package oneview
+
+import (
+	"errors"
+	"testing"
+)
+
+//testQueryServer  - блейд с двумя контроллерами: отказавший SSD и исправный HDD на разных дисках
+func testQueryServer() *ServerHardware {
+	srv := &ServerHardware{}
+	srv.Base.Model = "ProLiant BL460c Gen10"
+	srv.Base.MemoryMb = 262144
+	srv.Base.PowerState = "On"
+	srv.Base.Status = "Warning"
+	srv.Memory.Data = []MemoryModule{
+		{Name: "proc1dimm1", CapacityMiB: 32768, Status: MemoryStatus{Health: "OK"}},
+		{Name: "proc2dimm1", CapacityMiB: 32768, Status: MemoryStatus{Health: "OK"}},
+	}
+	srv.Storage.Data = []LocalStorage{
+		{
+			Model:             "Smart Array P840 Controller",
+			EncryptionEnabled: true,
+			Status:            Status{Health: "OK"},
+			PhysicalDrives: []LocalPhysicalDrive{
+				{MediaType: "SSD", CapacityMiB: 915715, EncryptedDrive: true, Status: Status{Health: "Failed"}},
+				{MediaType: "HDD", CapacityMiB: 3815447, EncryptedDrive: true, Status: Status{Health: "OK"}},
+			},
+		},
+		{
+			Model:  "HPE Smart Array P204i-c SR Gen10",
+			Status: Status{Health: "OK"},
+			PhysicalDrives: []LocalPhysicalDrive{
+				{MediaType: "HDD", CapacityMiB: 3815447, Status: Status{Health: "OK"}},
+			},
+		},
+	}
+	return srv
+}
+
+func TestQueryMatch(t *testing.T) {
+	srv := testQueryServer()
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`model ~ "bl460c"`, true},
+		{`model !~ "bl460c"`, false},
+		{`model ~ "^DL"`, false},
+		{`memoryMb >= 262144`, true},
+		{`memoryMb > 262144`, false},
+		{`memoryMb == 262144 && powerState = "on"`, true},
+		{`health == "warning"`, true},
+		{`storage.drives.capacityMiB < 1000000`, true},
+		{`storage.drives.capacityMiB > 4000000`, false},
+		{`storage.encryptionEnabled == true`, true},
+		{`storage.encryptionEnabled == false`, true},
+		{`all(storage.encryptionEnabled == true)`, false},
+		{`not (powerState == "On")`, false},
+		{`! model ~ "DL" || memoryMb < 1`, true},
+		{`unknownField == "x"`, false},
+		{`unknownField != "x"`, false},
+
+		//сравнение по списку - хотя бы один элемент
+		{`storage.drives.health != "OK"`, true},
+		{`storage.drives.health == "OK"`, true},
+
+		//any/all по элементам списка
+		{`all(memory.health == "OK")`, true},
+		{`all(storage.drives.health == "OK")`, false},
+		{`all(storage.drives.health != "OK")`, false},
+		{`all(not (storage.drives.health == "OK"))`, false},
+		{`not all(storage.drives.health == "OK")`, true},
+		{`any(storage.drives.health != "OK")`, true},
+		{`any(not (storage.drives.health == "OK"))`, true},
+		{`any(storage.drives.health != "OK" and storage.drives.mediaType == "SSD")`, true},
+		{`any(storage.drives.health != "OK" and storage.drives.mediaType == "HDD")`, false},
+		{`all(storage.drives.health == "OK" or storage.drives.mediaType == "SSD")`, true},
+		{`any(storage.model ~ "P840" and storage.drives.encryptedDrive == false)`, false},
+		{`any(storage.model ~ "P204i" and storage.drives.encryptedDrive == false)`, true},
+		{`any(storage.model ~ "P840" and all(storage.drives.encryptedDrive == true))`, true},
+		{`all(storage.logicalDrives.raid == "5")`, true},
+		{`any(storage.logicalDrives.raid == "5")`, false},
+
+		//all по пустому списку истинно, any - ложно
+		{`all(storage.logicalDrives.raid != "5")`, true},
+		{`all(storage.logicalDrives.raid == "5") and not any(storage.logicalDrives.raid == "5")`, true},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if got := q.Match(srv); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQueryUTF8(t *testing.T) {
+	srv := testQueryServer()
+	srv.Base.Name = "Сервер-БД ёлка"
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`name == "сервер-бд ЁЛКА"`, true},
+		{`name ~ "бд ё"`, true},
+		{`name !~ "^Сервер"`, false},
+		{`имя_поля == "x"`, false},
+		{`имя_поля != "x" or name ~ "ёлка$"`, true},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if got := q.Match(srv); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{``, 1},
+		{`model`, 6},
+		{`model ==`, 9},
+		{`model == BL460c`, 10},
+		{`model == "BL460c`, 10},
+		{`model ~ "("`, 9},
+		{`(model == "x"`, 14},
+		{`model == "x")`, 13},
+		{`model == "x" memoryMb > 1`, 14},
+		{`encryptionEnabled < true`, 19},
+		{`model & "x"`, 7},
+		{`model == "x" and`, 17},
+		{`storage..model == "x"`, 1},
+		{`all(model == "x"`, 17},
+		{`model == #`, 10},
+		{`name == "ёж" #`, 14},
+		{`имя == "ёж" and`, 16},
+		{`имя == «ёж»`, 8},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("ParseQuery(%q): got %v, want *ParseError", tt.query, err)
+			continue
+		}
+		if perr.Pos != tt.pos {
+			t.Errorf("ParseQuery(%q): error at %d (%s), want %d", tt.query, perr.Pos, perr.Msg, tt.pos)
+		}
+	}
+}
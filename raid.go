@@ -0,0 +1,193 @@
+package oneview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//StorageRiskType  - вид рискованной конфигурации хранилища
+type StorageRiskType string
+
+const (
+	RiskRaid0         StorageRiskType = "Raid0"         //логический диск без избыточности
+	RiskRaid5LargeHDD StorageRiskType = "Raid5LargeHDD" //RAID 5 на HDD большой емкости, высокий риск при перестроении
+	RiskDegraded      StorageRiskType = "Degraded"      //логический диск в деградированном состоянии или с отказавшими дисками
+	RiskMixedMedia    StorageRiskType = "MixedMedia"    //в логическом диске смешаны HDD и SSD
+	RiskMixedModel    StorageRiskType = "MixedModel"    //в логическом диске смешаны разные модели дисков
+	RiskNoSpare       StorageRiskType = "NoSpare"       //на контроллере с отказоустойчивыми томами нет диска горячей замены
+)
+
+//DefaultLargeHDDCapacityMiB  - емкость HDD начиная с которой RAID 5 считается рискованным (2 TiB)
+const DefaultLargeHDDCapacityMiB = 2 * 1024 * 1024
+
+//StorageRiskOptions  - параметры анализа
+type StorageRiskOptions struct {
+	LargeHDDCapacityMiB float64 //порог емкости HDD для RAID 5, если 0 то DefaultLargeHDDCapacityMiB
+}
+
+//StorageRisk  - найденная рискованная конфигурация
+type StorageRisk struct {
+	ServerSerialNumber string          `json:"ServerSerialNumber"` //серийный номер сервера
+	ServerName         string          `json:"ServerName"`         //имя сервера в OneView
+	Controller         string          `json:"Controller"`         //расположение контроллера "Slot 3"
+	LogicalDriveNumber int             `json:"LogicalDriveNumber"` //номер логического диска, 0 для рисков уровня контроллера
+	Raid               string          `json:"Raid"`               //уровень RAID
+	Type               StorageRiskType `json:"Type"`               //вид риска
+	Description        string          `json:"Description"`        //описание
+	Drives             []string        `json:"Drives"`             //расположение дисков, к которым относится риск
+}
+
+//ControllerCapacity  - использование емкости дисков контроллера
+type ControllerCapacity struct {
+	ServerSerialNumber    string  `json:"ServerSerialNumber"`
+	ServerName            string  `json:"ServerName"`
+	Controller            string  `json:"Controller"`
+	Model                 string  `json:"Model"`
+	PhysicalCapacityMiB   float64 `json:"PhysicalCapacityMiB"`   //суммарная емкость физических дисков
+	AssignedCapacityMiB   float64 `json:"AssignedCapacityMiB"`   //емкость дисков входящих в логические диски
+	SpareCapacityMiB      float64 `json:"SpareCapacityMiB"`      //емкость дисков горячей замены
+	UnassignedCapacityMiB float64 `json:"UnassignedCapacityMiB"` //емкость дисков не входящих ни в один логический диск
+}
+
+//Utilization  - доля емкости, назначенной логическим дискам
+func (c ControllerCapacity) Utilization() float64 {
+	if c.PhysicalCapacityMiB == 0 {
+		return 0
+	}
+	return c.AssignedCapacityMiB / c.PhysicalCapacityMiB
+}
+
+//StorageRiskReport  - результат анализа
+type StorageRiskReport struct {
+	Risks    []StorageRisk        `json:"Risks"`
+	Capacity []ControllerCapacity `json:"Capacity"`
+}
+
+//normalizeRaid  - приведение уровня RAID к виду "5", "10", "1ADM"
+func normalizeRaid(raid string) string {
+	raid = strings.ToUpper(strings.TrimSpace(raid))
+	raid = strings.TrimPrefix(raid, "RAID")
+	return strings.TrimSpace(raid)
+}
+
+//isSpareDrive  - диск является диском горячей замены
+func isSpareDrive(use string) bool {
+	return strings.Contains(strings.ToLower(use), "spare")
+}
+
+//AnalyzeStorageRisks  - анализ рискованных конфигураций логических дисков на всех загруженных серверах
+func (infra *OVInfrastructure) AnalyzeStorageRisks(opt StorageRiskOptions) StorageRiskReport {
+	report := StorageRiskReport{Risks: make([]StorageRisk, 0), Capacity: make([]ControllerCapacity, 0)}
+	for _, srv := range infra.Servers {
+		r := AnalyzeServerStorageRisks(srv, opt)
+		report.Risks = append(report.Risks, r.Risks...)
+		report.Capacity = append(report.Capacity, r.Capacity...)
+	}
+	return report
+}
+
+//AnalyzeServerStorageRisks  - анализ рискованных конфигураций логических дисков сервера
+func AnalyzeServerStorageRisks(srv *ServerHardware, opt StorageRiskOptions) StorageRiskReport {
+	report := StorageRiskReport{Risks: make([]StorageRisk, 0), Capacity: make([]ControllerCapacity, 0)}
+	if opt.LargeHDDCapacityMiB == 0 {
+		opt.LargeHDDCapacityMiB = DefaultLargeHDDCapacityMiB
+	}
+	sn := srv.Base.SerialNumber.String()
+	for _, ls := range srv.Storage.Data {
+		risk := func(ld LocalLogicalDrive, t StorageRiskType, desc string, drives []string) {
+			report.Risks = append(report.Risks, StorageRisk{
+				ServerSerialNumber: sn,
+				ServerName:         srv.Base.Name,
+				Controller:         ls.Location,
+				LogicalDriveNumber: ld.LogicalDriveNumber,
+				Raid:               ld.Raid,
+				Type:               t,
+				Description:        desc,
+				Drives:             drives,
+			})
+		}
+
+		assignedLoc := make(map[string]bool) //диски логических дисков по разобранному расположению
+		assignedSN := make(map[string]bool)  //и по серийному номеру, пустые номера не учитываются
+		redundant := false
+		for _, ld := range ls.LogicalDrives {
+			raid := normalizeRaid(ld.Raid)
+			if raid != "0" {
+				redundant = true
+			}
+
+			media := make(map[string]bool)
+			models := make(map[string]bool)
+			failed := make([]string, 0)
+			largeHDD := false
+			for _, dd := range ld.DataDrives {
+				if dd.ParsedLocation.Valid() {
+					assignedLoc[dd.ParsedLocation.String()] = true
+				}
+				if dd.SerialNumber != "" {
+					assignedSN[dd.SerialNumber] = true
+				}
+				media[dd.MediaType] = true
+				models[dd.Model] = true
+				if dd.Status.Health != "" && dd.Status.Health != "OK" {
+					failed = append(failed, dd.Location)
+				}
+				if dd.MediaType == "HDD" && dd.CapacityMiB >= opt.LargeHDDCapacityMiB {
+					largeHDD = true
+				}
+			}
+
+			switch {
+			case raid == "0":
+				risk(ld, RiskRaid0, "Logical drive has no redundancy", nil)
+			case raid == "5" && largeHDD:
+				risk(ld, RiskRaid5LargeHDD, "RAID 5 on large HDDs, high risk of second failure during rebuild", nil)
+			}
+			if len(failed) > 0 || (ld.Status.Health != "" && ld.Status.Health != "OK") {
+				risk(ld, RiskDegraded, fmt.Sprintf("Logical drive status %s, %d failed member(s)", ld.Status.Health, len(failed)), failed)
+			}
+			if len(media) > 1 {
+				risk(ld, RiskMixedMedia, "Logical drive mixes media types "+joinKeys(media), nil)
+			}
+			if len(models) > 1 {
+				risk(ld, RiskMixedModel, "Logical drive mixes drive models "+joinKeys(models), nil)
+			}
+		}
+
+		capacity := ControllerCapacity{
+			ServerSerialNumber: sn,
+			ServerName:         srv.Base.Name,
+			Controller:         ls.Location,
+			Model:              ls.Model,
+		}
+		spare := false
+		for _, pd := range ls.PhysicalDrives {
+			capacity.PhysicalCapacityMiB += pd.CapacityMiB
+			switch {
+			case isSpareDrive(pd.DiskDriveUse):
+				spare = true
+				capacity.SpareCapacityMiB += pd.CapacityMiB
+			case (pd.ParsedLocation.Valid() && assignedLoc[pd.ParsedLocation.String()]) || (pd.SerialNumber != "" && assignedSN[pd.SerialNumber]):
+				capacity.AssignedCapacityMiB += pd.CapacityMiB
+			default:
+				capacity.UnassignedCapacityMiB += pd.CapacityMiB
+			}
+		}
+		if redundant && !spare {
+			risk(LocalLogicalDrive{}, RiskNoSpare, "Controller has redundant logical drives but no spare drive", nil)
+		}
+		report.Capacity = append(report.Capacity, capacity)
+	}
+	return report
+}
+
+//joinKeys  - отсортированный список ключей через запятую
+func joinKeys(m map[string]bool) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,36 @@
+package oneview
+
+import "testing"
+
+func TestAnalyzeServerStorageCapacity(t *testing.T) {
+	ls := LocalStorage{
+		Location: "Slot 3",
+		LogicalDrives: []LocalLogicalDrive{{
+			Raid: "RAID 1",
+			DataDrives: []LogicalDataDrive{
+				{Location: "1I:1:1", MediaType: "HDD", CapacityMiB: 1000},
+				{Location: "1I:1:2", MediaType: "HDD", CapacityMiB: 1000},
+				{Location: "bad", SerialNumber: "SN4", MediaType: "HDD", CapacityMiB: 1000},
+			},
+		}},
+		PhysicalDrives: []LocalPhysicalDrive{
+			{Location: "1I:1:1", CapacityMiB: 1000},
+			{Location: "1I:1:2", CapacityMiB: 1000},
+			{Location: "1I:1:3", CapacityMiB: 1000},
+			{Location: "1I:1:4", SerialNumber: "SN4", CapacityMiB: 1000},
+			{Location: "1I:1:5", CapacityMiB: 1000, DiskDriveUse: "GlobalSpare"},
+		},
+	}
+	ls.decodeLocations()
+	srv := &ServerHardware{}
+	srv.Storage.Data = []LocalStorage{ls}
+
+	report := AnalyzeServerStorageRisks(srv, StorageRiskOptions{})
+	if len(report.Capacity) != 1 {
+		t.Fatalf("capacity records %d, want 1", len(report.Capacity))
+	}
+	c := report.Capacity[0]
+	if c.PhysicalCapacityMiB != 5000 || c.AssignedCapacityMiB != 3000 || c.SpareCapacityMiB != 1000 || c.UnassignedCapacityMiB != 1000 {
+		t.Errorf("capacity %+v, want 3000 assigned, 1000 spare, 1000 unassigned", c)
+	}
+}
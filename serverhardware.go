@@ -180,6 +180,7 @@ type LogicalDataDrive struct {
 	DiskDriveStatusReasons []string        `json:"DiskDriveStatusReasons"` //стаатусы "None"
 	DiskDriveUse           string          `json:"DiskDriveUse"`           //использование диска в томе "Data"
 	EncryptedDrive         bool            `json:"EncryptedDrive"`         //включено ли шифрование true, false
+	EncryptionStatus       string          `json:"EncryptionStatus"`       //состояние самошифрующегося диска "Unencrypted", "Unlocked", "Locked", "Foreign"
 	FirmwareVersion        FirmwareVersion `json:"FirmwareVersion"`        //текущая версия прошивки
 	Location               string          `json:"Location"`               //расположение "1I:1:7"
 	LocationFormat         string          `json:"LocationFormat"`         //формат строкти расположения "ControllerPort:Box:Bay"
@@ -187,6 +188,7 @@ type LogicalDataDrive struct {
 	Model                  string          `json:"Model"`                  //модель "MB4000JVYZQ"
 	SerialNumber           string          `json:"SerialNumber"`           //серийный номер "ZC17LE2X"
 	Status                 Status          `json:"Status"`                 //состояние
+	ParsedLocation         DriveLocation   `json:"ParsedLocation"`         //разобранное расположение
 }
 
 //LocalLogicalDrive  - описание тома
@@ -206,6 +208,7 @@ type LocalPhysicalDrive struct {
 	DiskDriveStatusReasons []string        `json:"DiskDriveStatusReasons"` //стаатусы "None"
 	DiskDriveUse           string          `json:"DiskDriveUse"`           //использование диска в томе "Data"
 	EncryptedDrive         bool            `json:"EncryptedDrive"`         //включено ли шифрование true, false
+	EncryptionStatus       string          `json:"EncryptionStatus"`       //состояние самошифрующегося диска "Unencrypted", "Unlocked", "Locked", "Foreign"
 	FirmwareVersion        FirmwareVersion `json:"FirmwareVersion"`        //текущая версия прошивки
 	Location               string          `json:"Location"`               //расположение "1I:1:7"
 	LocationFormat         string          `json:"LocationFormat"`         //формат строкти расположения "ControllerPort:Box:Bay"
@@ -213,6 +216,7 @@ type LocalPhysicalDrive struct {
 	Model                  string          `json:"Model"`                  //модель "MB4000JVYZQ"
 	SerialNumber           string          `json:"SerialNumber"`           //серийный номер "ZC17LE2X"
 	Status                 Status          `json:"Status"`                 //состояние
+	ParsedLocation         DriveLocation   `json:"ParsedLocation"`         //разобранное расположение
 }
 
 //LocalStorageEnclosure  - описание корзины для дисков
@@ -222,6 +226,7 @@ type LocalStorageEnclosure struct {
 	Location        string          `json:"Location"`        //расположение "1I:1"
 	LocationFormat  string          `json:"LocationFormat"`  //формат расположения "ControllerPort:Box"
 	Status          Status          `json:"Status"`          //состояние
+	ParsedLocation  DriveLocation   `json:"ParsedLocation"`  //разобранное расположение
 }
 
 //LocalStorage  - описание локального хранилища
@@ -230,6 +235,7 @@ type LocalStorage struct {
 	CacheMemorySizeMiB       int                     `json:"CacheMemorySizeMiB"`      //размер кэша контроллера в мегабайтах
 	CacheModuleSerialNumber  string                  `json:"CacheModuleSerialNumber"` //серийный номер контроллера
 	CacheModuleStatus        CacheModuleStatus       `json:"CacheModuleStatus"`       //состояние кэша
+	BackupPowerSourceStatus  string                  `json:"BackupPowerSourceStatus"` //батарея кэша (Smart Storage Battery) "Present", "NotPresent"
 	ControllerBoard          ControllerBoard         `json:"ControllerBoard"`
 	EncryptionCspTestPassed  bool                    `json:"EncryptionCspTestPassed"`  //тезультат тестового прохода
 	EncryptionEnabled        bool                    `json:"EncryptionEnabled"`        //шифрование включено?
@@ -276,162 +282,10 @@ func GetServerHardwareLocalStorage(c *ov.OVClient, uuid utils.Nstring) (ServerHa
 	if err := json.Unmarshal([]byte(data), &serverHardwareLocalStorage); err != nil {
 		return serverHardwareLocalStorage, err
 	}
-	return serverHardwareLocalStorage, nil
-}
-
-type Enclosure struct {
-	Type                 string      `json:"type"`
-	URI                  string      `json:"uri"`
-	Category             string      `json:"category"`
-	ETag                 time.Time   `json:"eTag"`
-	Created              time.Time   `json:"created"`
-	Modified             time.Time   `json:"modified"`
-	RefreshState         string      `json:"refreshState"`
-	StateReason          string      `json:"stateReason"`
-	EnclosureType        string      `json:"enclosureType"`
-	EnclosureTypeURI     string      `json:"enclosureTypeUri"`
-	EnclosureModel       string      `json:"enclosureModel"`
-	UUID                 string      `json:"uuid"`
-	SerialNumber         string      `json:"serialNumber"`
-	PartNumber           string      `json:"partNumber"`
-	ReconfigurationState string      `json:"reconfigurationState"`
-	UIDState             interface{} `json:"uidState"`
-	LicensingIntent      string      `json:"licensingIntent"`
-	DeviceBayCount       int         `json:"deviceBayCount"`
-	DeviceBays           []struct {
-		Type                                    string      `json:"type"`
-		BayNumber                               int         `json:"bayNumber"`
-		Model                                   interface{} `json:"model"`
-		DevicePresence                          string      `json:"devicePresence"`
-		ProfileURI                              interface{} `json:"profileUri"`
-		DeviceURI                               string      `json:"deviceUri"`
-		CoveredByProfile                        interface{} `json:"coveredByProfile"`
-		CoveredByDevice                         string      `json:"coveredByDevice"`
-		Ipv4Setting                             interface{} `json:"ipv4Setting"`
-		Ipv6Setting                             interface{} `json:"ipv6Setting"`
-		URI                                     string      `json:"uri"`
-		Category                                string      `json:"category"`
-		ETag                                    interface{} `json:"eTag"`
-		Created                                 interface{} `json:"created"`
-		Modified                                interface{} `json:"modified"`
-		AvailableForHalfHeightProfile           bool        `json:"availableForHalfHeightProfile"`
-		AvailableForFullHeightProfile           bool        `json:"availableForFullHeightProfile"`
-		DeviceBayType                           string      `json:"deviceBayType"`
-		DeviceFormFactor                        string      `json:"deviceFormFactor"`
-		BayPowerState                           string      `json:"bayPowerState"`
-		ChangeState                             string      `json:"changeState"`
-		AvailableForHalfHeightDoubleWideProfile bool        `json:"availableForHalfHeightDoubleWideProfile"`
-		AvailableForFullHeightDoubleWideProfile bool        `json:"availableForFullHeightDoubleWideProfile"`
-		UUID                                    interface{} `json:"uuid"`
-	} `json:"deviceBays"`
-	InterconnectBayCount int `json:"interconnectBayCount"`
-	InterconnectBays     []struct {
-		BayNumber              int         `json:"bayNumber"`
-		InterconnectURI        string      `json:"interconnectUri"`
-		LogicalInterconnectURI interface{} `json:"logicalInterconnectUri"`
-		InterconnectModel      string      `json:"interconnectModel"`
-		Ipv4Setting            interface{} `json:"ipv4Setting"`
-		Ipv6Setting            interface{} `json:"ipv6Setting"`
-		SerialNumber           string      `json:"serialNumber"`
-		InterconnectBayType    string      `json:"interconnectBayType"`
-		ChangeState            string      `json:"changeState"`
-		BayPowerState          string      `json:"bayPowerState"`
-	} `json:"interconnectBays"`
-	FanBayCount int `json:"fanBayCount"`
-	FanBays     []struct {
-		BayNumber       int    `json:"bayNumber"`
-		DevicePresence  string `json:"devicePresence"`
-		DeviceRequired  bool   `json:"deviceRequired"`
-		Status          string `json:"status"`
-		Model           string `json:"model"`
-		PartNumber      string `json:"partNumber"`
-		SparePartNumber string `json:"sparePartNumber"`
-		FanBayType      string `json:"fanBayType"`
-		ChangeState     string `json:"changeState"`
-		State           string `json:"state"`
-	} `json:"fanBays"`
-	PowerSupplyBayCount int `json:"powerSupplyBayCount"`
-	PowerSupplyBays     []struct {
-		BayNumber          int    `json:"bayNumber"`
-		DevicePresence     string `json:"devicePresence"`
-		Status             string `json:"status"`
-		Model              string `json:"model"`
-		SerialNumber       string `json:"serialNumber"`
-		PartNumber         string `json:"partNumber"`
-		SparePartNumber    string `json:"sparePartNumber"`
-		PowerSupplyBayType string `json:"powerSupplyBayType"`
-		ChangeState        string `json:"changeState"`
-	} `json:"powerSupplyBays"`
-	EnclosureGroupURI    interface{} `json:"enclosureGroupUri"`
-	FwBaselineURI        interface{} `json:"fwBaselineUri"`
-	FwBaselineName       interface{} `json:"fwBaselineName"`
-	IsFwManaged          bool        `json:"isFwManaged"`
-	ForceInstallFirmware bool        `json:"forceInstallFirmware"`
-	LogicalEnclosureURI  interface{} `json:"logicalEnclosureUri"`
-	ManagerBays          []struct {
-		BayNumber      int           `json:"bayNumber"`
-		ManagerType    string        `json:"managerType"`
-		UIDState       interface{}   `json:"uidState"`
-		BayPowerState  string        `json:"bayPowerState"`
-		FwVersion      string        `json:"fwVersion"`
-		DevicePresence string        `json:"devicePresence"`
-		Role           string        `json:"role"`
-		IPAddress      string        `json:"ipAddress"`
-		ChangeState    string        `json:"changeState"`
-		FwBuildDate    string        `json:"fwBuildDate"`
-		DhcpIpv6Enable bool          `json:"dhcpIpv6Enable"`
-		Ipv6Addresses  []interface{} `json:"ipv6Addresses"`
-		FqdnHostName   string        `json:"fqdnHostName"`
-		DhcpEnable     bool          `json:"dhcpEnable"`
-	} `json:"managerBays"`
-	SupportState               string `json:"supportState"`
-	SupportDataCollectionState string `json:"supportDataCollectionState"`
-	SupportDataCollectionType  string `json:"supportDataCollectionType"`
-	SupportDataCollectionsURI  string `json:"supportDataCollectionsUri"`
-	RemoteSupportURI           string `json:"remoteSupportUri"`
-	RemoteSupportSettings      struct {
-		RemoteSupportCurrentState string `json:"remoteSupportCurrentState"`
-		Destination               string `json:"destination"`
-	} `json:"remoteSupportSettings"`
-	CrossBars            []interface{} `json:"crossBars"`
-	Partitions           []interface{} `json:"partitions"`
-	ScopesURI            string        `json:"scopesUri"`
-	Status               string        `json:"status"`
-	Name                 string        `json:"name"`
-	State                string        `json:"state"`
-	Description          interface{}   `json:"description"`
-	StandbyOaPreferredIP string        `json:"standbyOaPreferredIP"`
-	ActiveOaPreferredIP  string        `json:"activeOaPreferredIP"`
-	AssetTag             string        `json:"assetTag"`
-	RackName             string        `json:"rackName"`
-	VcmDomainID          string        `json:"vcmDomainId"`
-	VcmDomainName        string        `json:"vcmDomainName"`
-	VcmMode              bool          `json:"vcmMode"`
-	VcmURL               string        `json:"vcmUrl"`
-	OaBays               int           `json:"oaBays"`
-	MigrationState       string        `json:"migrationState"`
-}
-
-func GetServerEnclosure(c *ov.OVClient, encuri utils.Nstring) (Enclosure, error) {
-
-	var (
-		encHardware Enclosure
-	)
-
-	// refresh login
-	c.RefreshLogin()
-	c.SetAuthHeaderOptions(c.GetAuthHeaderMap())
-
-	// rest call
-	data, err := c.RestAPICall(rest.GET, encuri.String(), nil)
-	if err != nil {
-		return encHardware, err
-	}
-
-	if err := json.Unmarshal([]byte(data), &encHardware); err != nil {
-		return encHardware, err
+	for i := range serverHardwareLocalStorage.Data {
+		serverHardwareLocalStorage.Data[i].decodeLocations() //разбор расположения дисков и корзин
 	}
-	return encHardware, nil
+	return serverHardwareLocalStorage, nil
 }
 
 type ServerSSOUrl struct {
@@ -451,8 +305,6 @@ func GetServerILOssoUrl(c *ov.OVClient, uuid utils.Nstring) (string, error) {
 		return ssoILOUrl.IloSsoURL, err
 	}
 
-	fmt.Println(string(data))
-
 	if err := json.Unmarshal([]byte(data), &ssoILOUrl); err != nil {
 		return ssoILOUrl.IloSsoURL, err
 	}
@@ -477,8 +329,6 @@ func GetServerjavaRemoteConsoleUrl(c *ov.OVClient, uuid utils.Nstring) (string,
 		return javaILOUrl.JavaRemoteConsoleUrl, err
 	}
 
-	fmt.Println(string(data))
-
 	if err := json.Unmarshal([]byte(data), &javaILOUrl); err != nil {
 		return javaILOUrl.JavaRemoteConsoleUrl, err
 	}
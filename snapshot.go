@@ -0,0 +1,208 @@
+package oneview
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//snapshotVersion  - версия формата файла снимка
+const snapshotVersion = 1
+
+//Snapshot  - снимок загруженной инфраструктуры для сохранения в файл, учетные данные не сохраняются
+type Snapshot struct {
+	Version              int                      `json:"version"`
+	Created              time.Time                `json:"created"`
+	Endpoints            []EndpointStatus         `json:"endpoints"`
+	Servers              []*ServerHardware        `json:"servers"`
+	Enclosures           []*Enclosure             `json:"enclosures"`
+	Interconnects        []*Interconnect          `json:"interconnects,omitempty"`
+	LogicalInterconnects []*LogicalInterconnect   `json:"logicalInterconnects,omitempty"`
+	UplinkSets           []*UplinkSet             `json:"uplinkSets,omitempty"`
+	Profiles             []*ServerProfile         `json:"profiles,omitempty"`
+	ProfileTemplates     []*ServerProfileTemplate `json:"profileTemplates,omitempty"`
+}
+
+//Snapshot  - снимок загруженной инфраструктуры, корзины включают привязанные к серверам
+func (infra *OVInfrastructure) Snapshot() Snapshot {
+	return Snapshot{
+		Version:              snapshotVersion,
+		Created:              time.Now(),
+		Endpoints:            infra.EndpointStatuses(),
+		Servers:              infra.Servers,
+		Enclosures:           infra.enclosureList(),
+		Interconnects:        infra.Interconnects,
+		LogicalInterconnects: infra.LogicalInterconnects,
+		UplinkSets:           infra.UplinkSets,
+		Profiles:             infra.Profiles,
+		ProfileTemplates:     infra.ProfileTemplates,
+	}
+}
+
+//SaveSnapshot  - сохранение снимка инфраструктуры в файл JSON
+func (infra *OVInfrastructure) SaveSnapshot(path string) error {
+	data, err := json.MarshalIndent(infra.Snapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+//Infrastructure  - инфраструктура из снимка с восстановленными связями серверов с корзинами и профилями,
+//точки подключения восстанавливаются без учетных данных
+func (s *Snapshot) Infrastructure() *OVInfrastructure {
+	infra := &OVInfrastructure{}
+	infra.Init()
+	for i := range s.Endpoints {
+		status := s.Endpoints[i]
+		ep := &ovEndpoint{endpoint: status.Endpoint, site: status.Site}
+		infra.endpoints = append(infra.endpoints, ep)
+		infra.crawlStatus[ep.endpoint] = &status
+	}
+	infra.Servers = append(infra.Servers, s.Servers...)
+	infra.ServersCount = len(infra.Servers)
+	infra.Enclosures = append(infra.Enclosures, s.Enclosures...)
+	infra.EnclosuresCount = len(infra.Enclosures)
+	infra.Interconnects = append(infra.Interconnects, s.Interconnects...)
+	infra.LogicalInterconnects = append(infra.LogicalInterconnects, s.LogicalInterconnects...)
+	infra.UplinkSets = append(infra.UplinkSets, s.UplinkSets...)
+	infra.Profiles = append(infra.Profiles, s.Profiles...)
+	infra.ProfileTemplates = append(infra.ProfileTemplates, s.ProfileTemplates...)
+	infra.linkEnclosures()
+	infra.linkProfiles()
+	return infra
+}
+
+//LoadSnapshot  - загрузка снимка инфраструктуры из файла, возвращает инфраструктуру и время создания снимка
+func LoadSnapshot(path string) (*OVInfrastructure, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, time.Time{}, err
+	}
+	if s.Version != snapshotVersion {
+		return nil, time.Time{}, errors.New("Unsupported snapshot version " + strconv.Itoa(s.Version))
+	}
+	return s.Infrastructure(), s.Created, nil
+}
+
+//NewSnapshotCrawler  - источник данных для API и метрик с неизменным снимком, например загруженным LoadSnapshot,
+//Run только ожидает отмены контекста, Refresh возвращает ошибку
+func NewSnapshotCrawler(infra *OVInfrastructure, created time.Time) *Crawler {
+	infra.Index()
+	return &Crawler{
+		static:      true,
+		infra:       infra,
+		updated:     created,
+		errorsTotal: make(map[string]int),
+	}
+}
+
+//FieldChange  - изменение поля сервера между снимками
+type FieldChange struct {
+	Field string `json:"Field"` //"romVersion", "memory[PROC1 DIMM 1].partNumber", "drives[1I:1:2].serialNumber"
+	Old   string `json:"Old"`
+	New   string `json:"New"`
+}
+
+//ServerChange  - изменение сервера между снимками
+type ServerChange struct {
+	SerialNumber string        `json:"SerialNumber"`
+	Name         string        `json:"Name"`
+	Endpoint     string        `json:"Endpoint"`
+	Kind         string        `json:"Kind"` //"added", "removed", "changed"
+	Changes      []FieldChange `json:"Changes,omitempty"`
+}
+
+//serverDiffFields  - сравниваемые поля сервера, модули памяти по расположению, диски по контроллеру и расположению
+func serverDiffFields(srv *ServerHardware) map[string]string {
+	fields := map[string]string{
+		"endpoint":          srv.Endpoint,
+		"name":              srv.Base.Name,
+		"model":             srv.Base.Model,
+		"status":            srv.Base.Status,
+		"powerState":        srv.Base.PowerState,
+		"romVersion":        srv.Base.RomVersion,
+		"mpFirmwareVersion": srv.Base.MpFirwareVersion,
+		"memoryMb":          strconv.Itoa(srv.Base.MemoryMb),
+		"processorType":     srv.Base.ProcessorType,
+		"processorCount":    strconv.Itoa(srv.Base.ProcessorCount),
+	}
+	if srv.Profile != nil {
+		fields["profile"] = srv.Profile.Name
+	}
+	for _, m := range srv.Memory.Data {
+		key := "memory[" + m.DeviceLocator + "]."
+		fields[key+"partNumber"] = m.PartNumber
+		fields[key+"capacityMiB"] = strconv.Itoa(m.CapacityMiB)
+		fields[key+"health"] = m.Status.Health
+	}
+	for _, ctrl := range srv.Storage.Data {
+		key := "controllers[" + ctrl.Location + "]."
+		fields[key+"model"] = ctrl.Model
+		fields[key+"serialNumber"] = ctrl.SerialNumber
+		fields[key+"firmware"] = ctrl.FirmwareVersion.Current.VersionString
+		fields[key+"health"] = ctrl.Status.Health
+		for _, d := range ctrl.PhysicalDrives {
+			key := "drives[" + ctrl.Location + " " + d.Location + "]."
+			fields[key+"serialNumber"] = d.SerialNumber
+			fields[key+"model"] = d.Model
+			fields[key+"firmware"] = d.FirmwareVersion.Current.VersionString
+			fields[key+"health"] = d.Status.Health
+		}
+	}
+	return fields
+}
+
+//DiffServers  - изменения серверов между двумя снимками по серийному номеру, упорядоченные по серийному номеру
+func DiffServers(old *OVInfrastructure, new *OVInfrastructure) []ServerChange {
+	oldIx := old.Index()
+	newIx := new.Index()
+	list := make([]ServerChange, 0)
+
+	for _, srv := range new.Servers {
+		sn := srv.Base.SerialNumber.String()
+		prev, ok := oldIx.BySerial(sn)
+		if !ok {
+			list = append(list, ServerChange{SerialNumber: sn, Name: srv.Base.Name, Endpoint: srv.Endpoint, Kind: "added"})
+			continue
+		}
+		before := serverDiffFields(prev)
+		after := serverDiffFields(srv)
+		keys := make([]string, 0)
+		for k := range before {
+			keys = append(keys, k)
+		}
+		for k := range after {
+			if _, ok := before[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		changes := make([]FieldChange, 0)
+		for _, k := range keys {
+			if before[k] != after[k] {
+				changes = append(changes, FieldChange{Field: k, Old: before[k], New: after[k]})
+			}
+		}
+		if len(changes) > 0 {
+			list = append(list, ServerChange{SerialNumber: sn, Name: srv.Base.Name, Endpoint: srv.Endpoint, Kind: "changed", Changes: changes})
+		}
+	}
+	for _, srv := range old.Servers {
+		sn := srv.Base.SerialNumber.String()
+		if _, ok := newIx.BySerial(sn); !ok {
+			list = append(list, ServerChange{SerialNumber: sn, Name: srv.Base.Name, Endpoint: srv.Endpoint, Kind: "removed"})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].SerialNumber < list[j].SerialNumber
+	})
+	return list
+}
@@ -0,0 +1,90 @@
+package oneview
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/HewlettPackard/oneview-golang/utils"
+)
+
+func TestSnapshotRoundTripHasNoCredentials(t *testing.T) {
+	infra := testCredentialInfra()
+	infra.AddEndpoint("ov1.example.com", "LOCAL", "ovadmin", "s3cr3t-pass")
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := infra.SaveSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"s3cr3t-pass", "ovadmin", `"Password"`, `"User"`} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("snapshot contains %s", secret)
+		}
+	}
+
+	restored, _, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := restored.FindServerHardwareSN("CZ0000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if srv.Base.Client != nil {
+		t.Errorf("restored server has a OneView client")
+	}
+	if srv.Base.Name != "enc1, bay 1" || srv.Endpoint != "ov1.example.com" {
+		t.Errorf("restored server %q from %q", srv.Base.Name, srv.Endpoint)
+	}
+}
+
+//testDiffInfra  - инфраструктура из серверов с заданными серийным номером, версией ROM и профилем
+func testDiffInfra(servers ...[3]string) *OVInfrastructure {
+	infra := &OVInfrastructure{}
+	infra.Init()
+	for _, s := range servers {
+		srv := &ServerHardware{Endpoint: "ov1.example.com"}
+		srv.Base.SerialNumber = utils.Nstring(s[0])
+		srv.Base.Name = "srv-" + s[0]
+		srv.Base.RomVersion = s[1]
+		if s[2] != "" {
+			srv.Profile = &ServerProfile{Name: s[2]}
+		}
+		infra.Servers = append(infra.Servers, srv)
+	}
+	return infra
+}
+
+func TestDiffServers(t *testing.T) {
+	old := testDiffInfra(
+		[3]string{"SN1", "U30 v2.40", "web1"},
+		[3]string{"SN2", "U30 v2.40", "db1"},
+		[3]string{"SN3", "U30 v2.40", ""},
+		[3]string{"SN4", "U30 v2.40", "app1"},
+	)
+	new := testDiffInfra(
+		[3]string{"SN1", "U30 v2.40", "web1"},
+		[3]string{"SN2", "U30 v2.60", "db1"},
+		[3]string{"SN5", "U30 v2.60", ""},
+		[3]string{"SN4", "U30 v2.40", ""},
+	)
+	want := []ServerChange{
+		{SerialNumber: "SN2", Name: "srv-SN2", Endpoint: "ov1.example.com", Kind: "changed",
+			Changes: []FieldChange{{Field: "romVersion", Old: "U30 v2.40", New: "U30 v2.60"}}},
+		{SerialNumber: "SN3", Name: "srv-SN3", Endpoint: "ov1.example.com", Kind: "removed"},
+		{SerialNumber: "SN4", Name: "srv-SN4", Endpoint: "ov1.example.com", Kind: "changed",
+			Changes: []FieldChange{{Field: "profile", Old: "app1", New: ""}}},
+		{SerialNumber: "SN5", Name: "srv-SN5", Endpoint: "ov1.example.com", Kind: "added"},
+	}
+	if got := DiffServers(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffServers:\n got %+v\nwant %+v", got, want)
+	}
+	if got := DiffServers(old, old); len(got) != 0 {
+		t.Errorf("DiffServers of the same snapshot: %+v", got)
+	}
+}
@@ -0,0 +1,134 @@
+package oneview
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HewlettPackard/oneview-golang/ov"
+	"github.com/HewlettPackard/oneview-golang/rest"
+)
+
+//ServerSupport  - состояние удаленной поддержки HPE и сбора диагностических данных сервера
+type ServerSupport struct {
+	SupportState               string `json:"supportState"`
+	SupportDataCollectionState string `json:"supportDataCollectionState"`
+	SupportDataCollectionType  string `json:"supportDataCollectionType"`
+	SupportDataCollectionsURI  string `json:"supportDataCollectionsUri"`
+	RemoteSupportURI           string `json:"remoteSupportUri"`
+}
+
+//serverHardwareSupport  - состояние удаленной поддержки из уже загруженного описания сервера, без запроса к OneView
+func serverHardwareSupport(base ov.ServerHardware) ServerSupport {
+	return ServerSupport{
+		SupportState:               base.SupportState,
+		SupportDataCollectionState: base.SupportDataCollectionState,
+		SupportDataCollectionType:  base.SupportDataCollectionType,
+		SupportDataCollectionsURI:  base.SupportDataCollectionsUri.String(),
+		RemoteSupportURI:           base.RemoteSupportUri.String(),
+	}
+}
+
+//SupportStatus  - состояние удаленной поддержки сервера или корзины
+type SupportStatus struct {
+	Kind                       string `json:"Kind"` //"server" или "enclosure"
+	Name                       string `json:"Name"`
+	SerialNumber               string `json:"SerialNumber"`
+	SupportState               string `json:"SupportState"`
+	RemoteSupportState         string `json:"RemoteSupportState"`
+	SupportDataCollectionState string `json:"SupportDataCollectionState"`
+	Registered                 bool   `json:"Registered"`       //зарегистрирован в удаленной поддержке HPE
+	CollectionFailed           bool   `json:"CollectionFailed"` //последний сбор диагностических данных завершился ошибкой
+}
+
+//supportRegistered  - состояние означает регистрацию в удаленной поддержке
+func supportRegistered(state string) bool {
+	return state == "Enabled" || state == "Registered"
+}
+
+//supportCollectionFailed  - состояние сбора диагностических данных означает ошибку
+func supportCollectionFailed(state string) bool {
+	return strings.Contains(state, "Fail") || strings.Contains(state, "Error")
+}
+
+//SupportReport  - состояние удаленной поддержки всех серверов и корзин, отсортированное по типу и имени
+func (infra *OVInfrastructure) SupportReport() []SupportStatus {
+	list := make([]SupportStatus, 0)
+	for _, srv := range infra.Servers {
+		list = append(list, SupportStatus{
+			Kind:                       "server",
+			Name:                       srv.Base.Name,
+			SerialNumber:               srv.Base.SerialNumber.String(),
+			SupportState:               srv.Support.SupportState,
+			SupportDataCollectionState: srv.Support.SupportDataCollectionState,
+			Registered:                 supportRegistered(srv.Support.SupportState),
+			CollectionFailed:           supportCollectionFailed(srv.Support.SupportDataCollectionState),
+		})
+	}
+	for _, enc := range infra.enclosureList() {
+		state := enc.RemoteSupportSettings.RemoteSupportCurrentState
+		list = append(list, SupportStatus{
+			Kind:                       "enclosure",
+			Name:                       enc.Name,
+			SerialNumber:               enc.SerialNumber,
+			SupportState:               enc.SupportState,
+			RemoteSupportState:         state,
+			SupportDataCollectionState: enc.SupportDataCollectionState,
+			Registered:                 supportRegistered(state) || (state == "" && supportRegistered(enc.SupportState)),
+			CollectionFailed:           supportCollectionFailed(enc.SupportDataCollectionState),
+		})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind > list[j].Kind
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+//SupportProblems  - серверы и корзины без регистрации в удаленной поддержке или с ошибкой сбора данных
+func (infra *OVInfrastructure) SupportProblems() []SupportStatus {
+	list := make([]SupportStatus, 0)
+	for _, s := range infra.SupportReport() {
+		if !s.Registered || s.CollectionFailed {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+//collectSupportData  - запуск сбора диагностических данных и ожидание завершения задачи
+func collectSupportData(c *ov.OVClient, collectionsURI string, deviceURI string, timeout time.Duration) (TaskResult, error) {
+	if collectionsURI == "" {
+		return TaskResult{}, errors.New("Support data collection is not available for " + deviceURI)
+	}
+	return runTaskTimeout(c, rest.POST, collectionsURI, map[string]string{"deviceUri": deviceURI}, timeout)
+}
+
+//CollectEnclosureSupportData  - сбор диагностических данных корзины, корзина задается именем, серийным номером или UUID
+func (infra *OVInfrastructure) CollectEnclosureSupportData(enclosure string, timeout time.Duration) (TaskResult, error) {
+	enc, err := infra.FindEnclosure(enclosure)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	c, err := infra.clientFor(enc.Endpoint)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	return collectSupportData(c, enc.SupportDataCollectionsURI, enc.URI, timeout)
+}
+
+//CollectServerSupportData  - сбор диагностических данных сервера по серийному номеру
+func (infra *OVInfrastructure) CollectServerSupportData(sn string, timeout time.Duration) (TaskResult, error) {
+	srv, err := infra.FindServerHardwareSN(sn)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	c, err := infra.clientFor(srv.Endpoint)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	return collectSupportData(c, srv.Support.SupportDataCollectionsURI, srv.Base.URI.String(), timeout)
+}
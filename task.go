@@ -0,0 +1,269 @@
+package oneview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HewlettPackard/oneview-golang/ov"
+	"github.com/HewlettPackard/oneview-golang/rest"
+)
+
+//TaskPollInterval  - интервал опроса состояния задачи
+var TaskPollInterval = 5 * time.Second
+
+//TaskError  - ошибка, возвращенная OneView в задаче
+type TaskError struct {
+	ErrorCode          string   `json:"errorCode"`
+	Message            string   `json:"message"`
+	Details            string   `json:"details"`
+	RecommendedActions []string `json:"recommendedActions"`
+}
+
+//Error  - текст ошибки вида "CODE: message"
+func (e TaskError) Error() string {
+	if e.ErrorCode == "" {
+		return e.Message
+	}
+	return e.ErrorCode + ": " + e.Message
+}
+
+//AssociatedResource  - объект, над которым выполняется задача
+type AssociatedResource struct {
+	ResourceName     string `json:"resourceName"`
+	ResourceURI      string `json:"resourceUri"`
+	ResourceCategory string `json:"resourceCategory"`
+}
+
+//Task  - задача OneView, возвращаемая операциями изменения
+type Task struct {
+	Type               string             `json:"type"`
+	URI                string             `json:"uri"`
+	Name               string             `json:"name"`
+	Owner              string             `json:"owner"`
+	TaskState          string             `json:"taskState"` //"New", "Running", "Completed", "Error", "Warning", "Killed", "Terminated"
+	TaskStatus         string             `json:"taskStatus"`
+	PercentComplete    int                `json:"percentComplete"`
+	AssociatedResource AssociatedResource `json:"associatedResource"`
+	TaskErrors         []TaskError        `json:"taskErrors"`
+	Created            string             `json:"created"`
+	Modified           string             `json:"modified"`
+}
+
+//Finished  - задача завершена (успешно или с ошибкой)
+func (t Task) Finished() bool {
+	switch t.TaskState {
+	case "Completed", "Error", "Warning", "Killed", "Terminated":
+		return true
+	}
+	return false
+}
+
+//Err  - ошибка, если задача завершилась неуспешно, nil для выполняющихся и успешных задач
+func (t Task) Err() error {
+	switch t.TaskState {
+	case "Error", "Killed", "Terminated":
+		return &TaskFailedError{Task: t}
+	}
+	return nil
+}
+
+//TaskFailedError  - задача завершилась неуспешно, Task содержит ошибки, возвращенные OneView
+type TaskFailedError struct {
+	Task Task
+}
+
+func (e *TaskFailedError) Error() string {
+	messages := make([]string, 0, len(e.Task.TaskErrors))
+	for _, te := range e.Task.TaskErrors {
+		messages = append(messages, te.Error())
+	}
+	if len(messages) == 0 {
+		messages = append(messages, e.Task.TaskStatus)
+	}
+	return "Task " + e.Task.URI + " " + e.Task.TaskState + ": " + strings.Join(messages, "; ")
+}
+
+//Unwrap  - первая ошибка задачи для errors.As
+func (e *TaskFailedError) Unwrap() error {
+	if len(e.Task.TaskErrors) == 0 {
+		return nil
+	}
+	return e.Task.TaskErrors[0]
+}
+
+//TaskResult  - результат выполнения задачи OneView
+type TaskResult struct {
+	TaskURI         string        `json:"TaskURI"`
+	State           string        `json:"State"`  //состояние задачи "Completed", "Error", ...
+	Status          string        `json:"Status"` //текстовое описание состояния
+	PercentComplete int           `json:"PercentComplete"`
+	Errors          []TaskError   `json:"Errors"`   //ошибки, возвращенные OneView
+	Duration        time.Duration `json:"Duration"` //время от отправки запроса до завершения
+}
+
+//NewTaskResult  - результат по последнему полученному состоянию задачи
+func NewTaskResult(task Task, started time.Time) TaskResult {
+	return TaskResult{
+		TaskURI:         task.URI,
+		State:           task.TaskState,
+		Status:          task.TaskStatus,
+		PercentComplete: task.PercentComplete,
+		Errors:          task.TaskErrors,
+		Duration:        time.Since(started),
+	}
+}
+
+//Err  - ошибка, если задача завершилась неуспешно
+func (r TaskResult) Err() error {
+	return Task{URI: r.TaskURI, TaskState: r.State, TaskStatus: r.Status, TaskErrors: r.Errors}.Err()
+}
+
+//GetTask  - запрос состояния задачи по uri
+func GetTask(c *ov.OVClient, uri string) (Task, error) {
+	var task Task
+
+	// refresh login
+	c.RefreshLogin()
+	c.SetAuthHeaderOptions(c.GetAuthHeaderMap())
+
+	// rest call
+	data, err := c.RestAPICall(rest.GET, uri, nil)
+	if err != nil {
+		return task, err
+	}
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		return task, err
+	}
+	return task, nil
+}
+
+//WaitTaskContext  - ожидание завершения задачи, progress (если задан) вызывается при каждом изменении состояния или процента выполнения
+func WaitTaskContext(ctx context.Context, c *ov.OVClient, uri string, progress func(Task)) (Task, error) {
+	var last Task
+
+	for {
+		task, err := GetTask(c, uri)
+		if err != nil {
+			return last, err
+		}
+		if progress != nil && (task.TaskState != last.TaskState || task.PercentComplete != last.PercentComplete || task.TaskStatus != last.TaskStatus) {
+			progress(task)
+		}
+		last = task
+		if task.Finished() {
+			return task, nil
+		}
+		select {
+		case <-ctx.Done():
+			return task, fmt.Errorf("Waiting for task %s: %w", uri, ctx.Err())
+		case <-time.After(TaskPollInterval):
+		}
+	}
+}
+
+//WaitTask  - ожидание завершения задачи не дольше timeout
+func WaitTask(c *ov.OVClient, uri string, timeout time.Duration) (Task, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return WaitTaskContext(ctx, c, uri, nil)
+}
+
+//TaskUpdate  - состояние задачи, передаваемое WatchTask
+type TaskUpdate struct {
+	Task Task
+	Err  error //ошибка запроса состояния или ожидания, последнее сообщение в канале
+}
+
+//WatchTask  - канал с изменениями состояния задачи, закрывается после завершения задачи, ошибки или отмены ctx
+func WatchTask(ctx context.Context, c *ov.OVClient, uri string) <-chan TaskUpdate {
+	updates := make(chan TaskUpdate)
+	go func() {
+		defer close(updates)
+		send := func(u TaskUpdate) {
+			select {
+			case updates <- u:
+			case <-ctx.Done():
+			}
+		}
+		_, err := WaitTaskContext(ctx, c, uri, func(t Task) { send(TaskUpdate{Task: t}) })
+		if err != nil {
+			send(TaskUpdate{Err: err})
+		}
+	}()
+	return updates
+}
+
+//submitTask  - вызов операции изменения и разбор задачи из ответа
+func submitTask(c *ov.OVClient, method rest.Method, uri string, body interface{}) (Task, error) {
+	var task Task
+
+	// refresh login
+	c.RefreshLogin()
+	c.SetAuthHeaderOptions(c.GetAuthHeaderMap())
+
+	// rest call
+	data, err := c.RestAPICall(method, uri, body)
+	if err != nil {
+		return task, err
+	}
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		return task, err
+	}
+	if task.URI == "" {
+		return task, errors.New("No task returned for " + uri)
+	}
+	return task, nil
+}
+
+//RunTask  - вызов операции изменения и ожидание завершения созданной задачи
+func RunTask(ctx context.Context, c *ov.OVClient, method rest.Method, uri string, body interface{}, progress func(Task)) (TaskResult, error) {
+	started := time.Now()
+	task, err := submitTask(c, method, uri, body)
+	if err != nil {
+		return NewTaskResult(task, started), err
+	}
+	task, err = WaitTaskContext(ctx, c, task.URI, progress)
+	result := NewTaskResult(task, started)
+	if err != nil {
+		return result, err
+	}
+	return result, task.Err()
+}
+
+//runTaskTimeout  - RunTask с ограничением времени ожидания
+func runTaskTimeout(c *ov.OVClient, method rest.Method, uri string, body interface{}, timeout time.Duration) (TaskResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return RunTask(ctx, c, method, uri, body, nil)
+}
+
+//EndpointTasks  - выполняющиеся задачи точки подключения
+type EndpointTasks struct {
+	Endpoint string `json:"Endpoint"`
+	Tasks    []Task `json:"Tasks"`
+	Err      string `json:"Err,omitempty"` //ошибка загрузки списка задач
+}
+
+//RunningTasks  - выполняющиеся задачи на всех точках подключения
+func (infra *OVInfrastructure) RunningTasks() []EndpointTasks {
+	list := make([]EndpointTasks, 0, len(infra.endpoints))
+	for _, endpoint := range infra.endpoints {
+		et := EndpointTasks{Endpoint: endpoint.endpoint, Tasks: make([]Task, 0)}
+		members, err := loadCollection(endpoint.client(), "/rest/tasks", []string{"taskState='Running'"})
+		if err != nil {
+			et.Err = err.Error()
+		}
+		for _, rec := range members {
+			task := Task{}
+			if err := json.Unmarshal(rec, &task); err == nil {
+				et.Tasks = append(et.Tasks, task)
+			}
+		}
+		list = append(list, et)
+	}
+	return list
+}
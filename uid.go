@@ -0,0 +1,114 @@
+package oneview
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/HewlettPackard/oneview-golang/ov"
+	"github.com/HewlettPackard/oneview-golang/rest"
+)
+
+//UIDState  - состояние индикатора UID
+type UIDState string
+
+const (
+	UIDOn    UIDState = "On"
+	UIDOff   UIDState = "Off"
+	UIDBlink UIDState = "Blink"
+)
+
+//patchOperation  - операция JSON Patch
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+//setUIDState  - изменение состояния индикатора UID сервера или корзины по uri
+func setUIDState(c *ov.OVClient, uri string, state UIDState, timeout time.Duration) (TaskResult, error) {
+	switch state {
+	case UIDOn, UIDOff, UIDBlink:
+	default:
+		return TaskResult{}, errors.New("Unknown UID state " + string(state))
+	}
+	patch := []patchOperation{{Op: "replace", Path: "/uidState", Value: string(state)}}
+	return runTaskTimeout(c, rest.PATCH, uri, patch, timeout)
+}
+
+//SetServerUIDState  - изменение состояния индикатора UID сервера с указанным серийным номером
+func (infra *OVInfrastructure) SetServerUIDState(sn string, state UIDState, timeout time.Duration) (TaskResult, error) {
+	srv, err := infra.FindServerHardwareSN(sn)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	c, err := infra.clientFor(srv.Endpoint)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	result, err := setUIDState(c, srv.Base.URI.String(), state, timeout)
+	if err == nil {
+		srv.Base.UidState = string(state)
+	}
+	return result, err
+}
+
+//SetEnclosureUIDState  - изменение состояния индикатора UID корзины, корзина задается именем, серийным номером или UUID
+func (infra *OVInfrastructure) SetEnclosureUIDState(enclosure string, state UIDState, timeout time.Duration) (TaskResult, error) {
+	enc, err := infra.FindEnclosure(enclosure)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	c, err := infra.clientFor(enc.Endpoint)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	result, err := setUIDState(c, enc.URI, state, timeout)
+	if err == nil {
+		enc.UIDState = string(state)
+	}
+	return result, err
+}
+
+//LocateServer  - включение индикаторов UID сервера и его корзины на время duration с последующим выключением,
+//выключение выполняется и при отмене ctx, timeout ограничивает ожидание каждой задачи OneView,
+//ошибка выключения сообщает, какой индикатор остался включенным
+func (infra *OVInfrastructure) LocateServer(ctx context.Context, sn string, duration time.Duration, timeout time.Duration) error {
+	srv, err := infra.FindServerHardwareSN(sn)
+	if err != nil {
+		return err
+	}
+	if _, err := infra.SetServerUIDState(sn, UIDOn, timeout); err != nil {
+		return err
+	}
+	enclosure := ""
+	if srv.Enclosure != nil {
+		enclosure = srv.Enclosure.UUID
+		if _, err := infra.SetEnclosureUIDState(enclosure, UIDOn, timeout); err != nil {
+			if _, offErr := infra.SetServerUIDState(sn, UIDOff, timeout); offErr != nil {
+				return errors.New(err.Error() + "; UID of server " + sn + " was left on: " + offErr.Error())
+			}
+			return err
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(duration):
+	}
+
+	msgs := make([]string, 0)
+	if _, err := infra.SetServerUIDState(sn, UIDOff, timeout); err != nil {
+		msgs = append(msgs, "UID of server "+sn+" was left on: "+err.Error())
+	}
+	if enclosure != "" {
+		if _, err := infra.SetEnclosureUIDState(enclosure, UIDOff, timeout); err != nil {
+			msgs = append(msgs, "UID of enclosure "+srv.Enclosure.Name+" was left on: "+err.Error())
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}